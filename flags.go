@@ -0,0 +1,263 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kozmaoliver/asciify/internal/converter"
+	"github.com/kozmaoliver/asciify/internal/debug"
+	"github.com/kozmaoliver/asciify/internal/imageio"
+	"github.com/kozmaoliver/asciify/internal/output"
+	"github.com/kozmaoliver/asciify/internal/palette"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+	"github.com/kozmaoliver/asciify/internal/theme"
+	"os"
+	"strings"
+)
+
+// renderFlags are the conversion and display flags shared by the
+// subcommands.
+type renderFlags struct {
+	debug           *bool
+	debugDir        *string
+	edgeCutoff      *float64
+	contrast        *float64
+	theme           *string
+	bg              *string
+	color           *bool
+	colors          *string
+	palette         *string
+	dither          *bool
+	bgDetect        *string
+	matte           *string
+	alphaThreshold  *int
+	chromaKey       *string
+	chromaTolerance *float64
+	noAutoOrient    *bool
+	transforms      *transformFlags
+}
+
+func addRenderFlags(flags *flag.FlagSet) *renderFlags {
+	return &renderFlags{
+		debug:           flags.Bool("debug", false, "Enable debug mode (saves intermediate images and logs)"),
+		debugDir:        flags.String("debug-dir", "debug_output", "Directory for debug output files"),
+		edgeCutoff:      flags.Float64("edge-cutoff", 90.0, "Edge detection threshold"),
+		contrast:        flags.Float64("contrast", 1.0, "Contrast factor applied after resizing (1 leaves the image unchanged)"),
+		theme:           flags.String("theme", "default", "Character theme: "+strings.Join(theme.Names(), ", ")),
+		bg:              flags.String("bg", "none", "Background color: none, black, or white (white inverts the character ramp)"),
+		color:           flags.Bool("color", false, "Enable colored output using original image colors"),
+		colors:          flags.String("colors", "auto", "Color depth: auto, truecolor, 256, 16, 8, or mono"),
+		palette:         flags.String("palette", "", "Quantize colors to a palette: "+strings.Join(palette.Names(), ", ")+", or a .gpl/hex palette file (implies -color)"),
+		dither:          flags.Bool("dither", false, "Apply Floyd-Steinberg dithering when quantizing to a palette"),
+		bgDetect:        flags.String("bg-detect", "auto", "Terminal background detection: auto, off, light, or dark (light and dark also override -bg)"),
+		matte:           flags.String("matte", "", "Composite transparent images onto this color (hex or name) instead of leaving transparent cells"),
+		alphaThreshold:  flags.Int("alpha-threshold", 128, "Pixels with alpha below this value (0-255) render as transparent cells"),
+		chromaKey:       flags.String("chroma-key", "", "Treat pixels of this color (hex or name) as transparent, e.g. green"),
+		chromaTolerance: flags.Float64("chroma-tolerance", 30.0, "Color distance (CIE76 delta E) within which pixels match -chroma-key"),
+		noAutoOrient:    flags.Bool("no-auto-orient", false, "Ignore the EXIF orientation of JPEG images"),
+		transforms:      addTransformFlags(flags),
+	}
+}
+
+// decodeOptions returns how images are decoded.
+func (f *renderFlags) decodeOptions() imageio.DecodeOptions {
+	return imageio.DecodeOptions{AutoOrient: !*f.noAutoOrient}
+}
+
+// setup initialises debug mode and resolves the flags into conversion
+// options, background and colour depth, exiting on invalid values.
+// outputPath and format describe where the result goes; an empty
+// outputPath means the terminal, which is then queried for its background
+// and cell size.
+func (f *renderFlags) setup(outputPath string, format output.Format) (converter.Options, terminal.BackgroundColor, terminal.ColorDepth) {
+	debug.Init(*f.debug, *f.debugDir)
+	toTerminal := outputPath == ""
+
+	colorDepth, err := terminal.ParseColorDepth(*f.colors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	debug.Log("Color depth: %s", colorDepth)
+
+	useColor := *f.color
+	var pal *palette.Palette
+	if *f.palette != "" {
+		pal, err = palette.Lookup(*f.palette)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading palette: %v\n", err)
+			os.Exit(1)
+		}
+		debug.Log("Palette: %s (%d colors)", pal.Name, len(pal.Colors))
+		useColor = true
+	}
+	if useColor && !colorAllowed(colorDepth, outputPath, format) {
+		debug.Log("Color output disabled: terminal has no colors")
+		useColor = false
+	}
+
+	t, err := theme.Lookup(*f.theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	bgColor, err := terminal.ParseBackgroundColor(*f.bg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -bg: %v\n", err)
+		os.Exit(1)
+	}
+
+	detectMode := *f.bgDetect
+	switch detectMode {
+	case "auto", "off", "light", "dark":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: -bg-detect: unknown mode %q (want auto, off, light or dark)\n", detectMode)
+		os.Exit(1)
+	}
+	if !toTerminal && detectMode == "auto" {
+		// Files are not displayed on this terminal, so its background is irrelevant.
+		detectMode = "off"
+	}
+	if lightBackground(detectMode, bgColor) {
+		debug.Log("Light background: inverting theme ramp")
+		t = theme.NewInvertedTheme(t)
+	}
+
+	stages, err := f.transforms.stages()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	autocrop, err := f.transforms.autocropMode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fit, err := f.transforms.fitMode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := converter.Options{
+		Theme:           t,
+		Stages:          stages,
+		Autocrop:        autocrop,
+		Fit:             fit,
+		EdgeCutoff:      *f.edgeCutoff,
+		Contrast:        *f.contrast,
+		Color:           useColor,
+		AlphaThreshold:  *f.alphaThreshold,
+		ChromaTolerance: *f.chromaTolerance,
+		Palette:         pal,
+		Dither:          *f.dither,
+	}
+	if *f.chromaKey != "" {
+		key, err := palette.ParseColor(*f.chromaKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -chroma-key: %v\n", err)
+			os.Exit(1)
+		}
+		opts.ChromaKey = key
+	}
+	if *f.matte != "" {
+		matte, err := palette.ParseColor(*f.matte)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -matte: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Matte = matte
+	}
+
+	if toTerminal && terminal.IsTerminal(os.Stdout) {
+		size, _ := terminal.GetTerminalSize()
+		opts.CellAspect = terminal.DetectCellAspect(size, terminal.DefaultQueryTimeout)
+		if opts.CellAspect > 0 {
+			debug.Log("Cell aspect ratio: %.3f", opts.CellAspect)
+		} else {
+			debug.Log("Cell aspect ratio unknown, assuming %.2f", imageio.CharAspectRatio)
+		}
+	}
+	return opts, bgColor, colorDepth
+}
+
+// colorAllowed reports whether output to outputPath in format can show
+// colours. Only escape-sequence output is limited by the terminal's colour
+// depth.
+func colorAllowed(depth terminal.ColorDepth, outputPath string, format output.Format) bool {
+	escapes := outputPath == "" || format == output.FormatANSI || format == output.FormatCast
+	return depth != terminal.DepthMono || !escapes
+}
+
+// transformFlags are the geometric transforms applied before resizing.
+type transformFlags struct {
+	crop      *string
+	rotate    *float64
+	flip      *string
+	zoom      *float64
+	zoomFocus *string
+	autocrop  *string
+	fit       *string
+}
+
+func addTransformFlags(flags *flag.FlagSet) *transformFlags {
+	return &transformFlags{
+		crop:      flags.String("crop", "", "Crop to x,y,w,h before resizing, in pixels or percent (e.g. 25%,25%,50%,50%)"),
+		rotate:    flags.Float64("rotate", 0, "Rotate clockwise by this many degrees (90, 180, 270 or any angle)"),
+		flip:      flags.String("flip", "", "Flip the image: h (horizontal), v (vertical) or hv"),
+		zoom:      flags.Float64("zoom", 1, "Magnify the image by this factor (at least 1) around -zoom-focus"),
+		zoomFocus: flags.String("zoom-focus", "50%,50%", "Focal point x,y for -zoom, in pixels or percent"),
+		fit:       flags.String("fit", "contain", "Fit to the terminal: contain (scale, leaving margins) or carve (remove low-energy seams to match its aspect ratio)"),
+		autocrop:  flags.String("autocrop", "off", "Trim the image by edge energy: off, borders (uniform margins), or saliency (also pick the busiest window matching the terminal)"),
+	}
+}
+
+// stages returns the transforms in the order they apply: crop, zoom,
+// rotate, flip. Autocrop runs after them, in the converter.
+func (f *transformFlags) stages() ([]imageio.Stage, error) {
+	var stages []imageio.Stage
+	if *f.crop != "" {
+		region, err := imageio.ParseRegion(*f.crop)
+		if err != nil {
+			return nil, fmt.Errorf("-crop: %w", err)
+		}
+		stages = append(stages, imageio.CropStage{Region: region})
+	}
+	if *f.zoom < 1 {
+		return nil, fmt.Errorf("-zoom: %g is less than 1", *f.zoom)
+	}
+	if *f.zoom > 1 {
+		focus, err := imageio.ParsePoint(*f.zoomFocus)
+		if err != nil {
+			return nil, fmt.Errorf("-zoom-focus: %w", err)
+		}
+		stages = append(stages, imageio.ZoomStage{Factor: *f.zoom, Focus: focus})
+	}
+	if *f.rotate != 0 {
+		stages = append(stages, imageio.RotateStage{Degrees: *f.rotate})
+	}
+	if *f.flip != "" {
+		flip, err := imageio.ParseFlip(*f.flip)
+		if err != nil {
+			return nil, fmt.Errorf("-flip: %w", err)
+		}
+		stages = append(stages, flip)
+	}
+	return stages, nil
+}
+
+func (f *transformFlags) fitMode() (imageio.FitMode, error) {
+	mode, err := imageio.ParseFitMode(*f.fit)
+	if err != nil {
+		return mode, fmt.Errorf("-fit: %w", err)
+	}
+	return mode, nil
+}
+
+func (f *transformFlags) autocropMode() (imageio.AutocropMode, error) {
+	mode, err := imageio.ParseAutocropMode(*f.autocrop)
+	if err != nil {
+		return mode, fmt.Errorf("-autocrop: %w", err)
+	}
+	return mode, nil
+}
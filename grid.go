@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kozmaoliver/asciify/internal/converter"
+	"github.com/kozmaoliver/asciify/internal/debug"
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/grid"
+	"github.com/kozmaoliver/asciify/internal/imageio"
+	"github.com/kozmaoliver/asciify/internal/output"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+	"os"
+)
+
+// runGrid implements "asciify grid": a contact sheet of thumbnails.
+func runGrid(args []string) {
+	flags := flag.NewFlagSet("grid", flag.ExitOnError)
+	render := addRenderFlags(flags)
+	columns := flags.Int("columns", 0, "Number of columns (0 fits as many -tile-width tiles as the width allows)")
+	tileWidth := flags.Int("tile-width", 24, "Tile width in characters when -columns is 0")
+	gutter := flags.Int("gutter", 2, "Space between tiles in characters")
+	captions := flags.Bool("captions", true, "Write the file name under each tile")
+	width := flags.Int("width", 0, "Total width in characters (default: terminal width)")
+	var outputPath string
+	flags.StringVar(&outputPath, "o", "", "Write output to a file instead of the terminal (\"-\" for stdout)")
+	flags.StringVar(&outputPath, "output", "", "Same as -o")
+	formatStr := flags.String("format", "auto", "Output file format, as for the main command")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s grid [flags] <image-path|directory> [...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	var format output.Format
+	if outputPath != "" {
+		format = output.FormatFromPath(outputPath)
+		if *formatStr != "auto" {
+			var err error
+			format, err = output.ParseFormat(*formatStr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	convertOpts, bgColor, colorDepth := render.setup(outputPath, format)
+
+	paths, err := imageio.ExpandPaths(flags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no images found\n")
+		os.Exit(1)
+	}
+
+	totalWidth := *width
+	if totalWidth <= 0 {
+		size, err := terminal.GetTerminalSize()
+		if err != nil {
+			debug.Log("Could not detect terminal size, using %d columns: %v", size.Width, err)
+		}
+		totalWidth = size.Width
+	}
+
+	layout := grid.Options{Columns: *columns, Gutter: *gutter}
+	if layout.Columns <= 0 {
+		layout.Columns = grid.Columns(totalWidth, *tileWidth, *gutter, len(paths))
+	}
+	layout.TileWidth = grid.TileWidth(totalWidth, layout.Columns, *gutter)
+	debug.Log("Grid: %d images, %d columns of %d characters", len(paths), layout.Columns, layout.TileWidth)
+
+	var tiles []*frame.Frame
+	var names []string
+	for _, path := range paths {
+		img, err := imageio.LoadImage(path, render.decodeOptions())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", path, err)
+			continue
+		}
+		// Tiles are bounded by their width; a square cell of that many
+		// lines leaves room for portrait images.
+		tiles = append(tiles, converter.Convert(img, layout.TileWidth, layout.TileWidth, convertOpts))
+		names = append(names, imageio.Name(path))
+	}
+	if len(tiles) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no images could be loaded\n")
+		os.Exit(1)
+	}
+	if !*captions {
+		names = nil
+	}
+	sheet := grid.Compose(tiles, names, layout)
+
+	if outputPath != "" {
+		opts := output.Options{
+			Background: bgColor,
+			Color:      convertOpts.Color,
+			Depth:      colorDepth,
+		}
+		debug.Log("Writing %s output to %s", format, outputPath)
+		if err := output.WriteAnimationFile(outputPath, []*frame.Frame{sheet}, nil, format, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	} else if err := terminal.RenderFrame(sheet, bgColor, convertOpts.Color, colorDepth); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering: %v\n", err)
+		os.Exit(1)
+	}
+
+	if debug.IsEnabled() {
+		debug.WriteLogsToFile("debug.log")
+	}
+}
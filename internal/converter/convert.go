@@ -0,0 +1,255 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/kozmaoliver/asciify/internal/debug"
+	"github.com/kozmaoliver/asciify/internal/edge"
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/imageio"
+	"github.com/kozmaoliver/asciify/internal/luminance"
+	"github.com/kozmaoliver/asciify/internal/palette"
+	"github.com/kozmaoliver/asciify/internal/theme"
+)
+
+// Options configures the image to ASCII conversion pipeline.
+type Options struct {
+	Theme      theme.Theme
+	EdgeCutoff float64
+
+	// Stages are geometric transforms applied in order before anything else.
+	Stages []imageio.Stage
+
+	// Autocrop trims borders, or picks the most salient window matching
+	// the output aspect ratio, after the stages.
+	Autocrop imageio.AutocropMode
+
+	// Fit selects how the image is fitted to width x height; FitCarve
+	// seam-carves it to the output aspect ratio before resizing.
+	Fit imageio.FitMode
+
+	// CellAspect is the width/height ratio of a terminal character cell;
+	// zero means imageio.CharAspectRatio.
+	CellAspect float64
+
+	// DisableEdges skips edge detection, leaving only the luminance ramp.
+	DisableEdges bool
+
+	// Contrast scales the resized image's contrast; 0 and 1 leave it unchanged.
+	Contrast float64
+
+	// Color stores the image colours in the frame and draws every cell
+	// with the theme's brightest character.
+	Color bool
+
+	// Pixels with alpha below AlphaThreshold become transparent cells.
+	AlphaThreshold int
+
+	// Matte, if set, composites the image onto a solid colour first.
+	Matte color.Color
+
+	// ChromaKey, if set, makes pixels within ChromaTolerance of it transparent.
+	ChromaKey       color.Color
+	ChromaTolerance float64
+
+	// Palette, if set, quantises the frame colours.
+	Palette *palette.Palette
+	Dither  bool
+}
+
+// Prepare applies the steps that work on whole source images to every
+// frame of an animation: the stages, autocrop, chroma key, matte and seam
+// carving. When all frames have the same size they are cropped to one
+// shared rectangle and carved along the same seams, so the picture does
+// not jump around or wobble between frames; frames of different sizes are
+// separate pictures and are handled on their own. It returns the
+// prepared frames and opts with those steps removed, ready for Convert.
+func Prepare(images []image.Image, width, height int, opts Options) ([]image.Image, Options) {
+	prepared := make([]image.Image, len(images))
+	for i, img := range images {
+		for j, stage := range opts.Stages {
+			img = stage.Apply(img)
+			bounds := img.Bounds()
+			debug.Log("Applied %s: %dx%d", stage.Name(), bounds.Dx(), bounds.Dy())
+			debug.SaveImage(img, fmt.Sprintf("01_stage%d_%s", j+1, stage.Name()))
+		}
+		prepared[i] = img
+	}
+
+	// The width to height ratio of the output area, in pixels.
+	aspect := float64(width) / (float64(height) / opts.cellAspect())
+
+	if opts.Autocrop != "" && opts.Autocrop != imageio.AutocropOff {
+		if sameBounds(prepared) {
+			crop := imageio.AutocropRect(prepared, opts.Autocrop, aspect)
+			for i, img := range prepared {
+				prepared[i] = imageio.Crop(img, crop)
+			}
+		} else {
+			for i, img := range prepared {
+				prepared[i] = imageio.Autocrop(img, opts.Autocrop, aspect)
+			}
+		}
+		bounds := prepared[0].Bounds()
+		debug.Log("Autocrop (%s): %dx%d", opts.Autocrop, bounds.Dx(), bounds.Dy())
+		debug.SaveImage(prepared[0], "01_autocrop")
+	}
+
+	for i, img := range prepared {
+		if opts.ChromaKey != nil {
+			debug.Log("Applying chroma key (tolerance %.1f)", opts.ChromaTolerance)
+			img = imageio.ChromaKey(img, opts.ChromaKey, opts.ChromaTolerance)
+			debug.SaveImage(img, "01_chroma_key")
+		}
+		if opts.Matte != nil {
+			debug.Log("Compositing onto matte")
+			img = imageio.Composite(img, opts.Matte)
+			debug.SaveImage(img, "01_matte")
+		}
+		prepared[i] = img
+	}
+
+	if opts.Fit == imageio.FitCarve {
+		// Carve at twice the output resolution; finer seams are wasted.
+		maxSide := 2 * max(width, int(float64(height)/opts.cellAspect()))
+		if sameBounds(prepared) {
+			prepared = imageio.CarveFrames(prepared, aspect, maxSide)
+		} else {
+			for i, img := range prepared {
+				prepared[i] = imageio.Carve(img, aspect, maxSide)
+			}
+		}
+		bounds := prepared[0].Bounds()
+		debug.Log("Seam carved to %dx%d", bounds.Dx(), bounds.Dy())
+		debug.SaveImage(prepared[0], "01_carved")
+	}
+
+	opts.Stages = nil
+	opts.Autocrop = imageio.AutocropOff
+	opts.ChromaKey = nil
+	opts.Matte = nil
+	opts.Fit = imageio.FitContain
+	return prepared, opts
+}
+
+// Convert runs the full pipeline on an image: geometric transforms, keying
+// and matting, seam carving, resizing to fit width x height cells,
+// luminance mapping, edge detection and palette quantisation. Intermediate
+// results are saved in debug mode. The frames of an animation should go
+// through Prepare first.
+func Convert(img image.Image, width, height int, opts Options) *frame.Frame {
+	bounds := img.Bounds()
+	debug.Log("Converting image: %dx%d", bounds.Dx(), bounds.Dy())
+	debug.SaveImage(img, "01_original")
+
+	prepared, opts := Prepare([]image.Image{img}, width, height, opts)
+	img = prepared[0]
+
+	// Resize for terminal
+	resized := imageio.ResizeForTerminal(img, width, height, opts.cellAspect())
+	resizedBounds := resized.Bounds()
+	debug.Log("Resized image: %dx%d", resizedBounds.Dx(), resizedBounds.Dy())
+	debug.SaveImage(resized, "02_resized")
+
+	if opts.Contrast > 0 && opts.Contrast != 1 {
+		debug.Log("Adjusting contrast: %.2f", opts.Contrast)
+		resized = imageio.Contrast(resized, opts.Contrast)
+		debug.SaveImage(resized, "02_contrast")
+	}
+
+	frameWidth := resizedBounds.Dx()
+	frameHeight := resizedBounds.Dy()
+	f := frame.New(frameWidth, frameHeight)
+
+	// Enable color storage if color output is requested
+	if opts.Color {
+		f.EnableColors()
+	}
+
+	resolver := NewResolver(opts.Theme, opts.EdgeCutoff)
+
+	// Step 1: Generate ASCII image based on luminance
+	debug.Log("Step 1: Generating ASCII from luminance")
+	brightestChar := opts.Theme.BrightestChar()
+	debug.Log("Theme characters: %d levels", len(opts.Theme.Characters()))
+
+	for y := 0; y < frameHeight; y++ {
+		for x := 0; x < frameWidth; x++ {
+			c := resized.At(resizedBounds.Min.X+x, resizedBounds.Min.Y+y)
+			if int(imageio.Alpha(c)) < opts.AlphaThreshold {
+				f.SetTransparent(x, y)
+				continue
+			}
+			c = imageio.Opaque(c)
+
+			if opts.Color {
+				f.SetColor(x, y, c)
+				f.Set(x, y, brightestChar)
+				continue
+			}
+
+			f.Set(x, y, resolver.Resolve(luminance.Luminance(c), edge.Edge{}))
+		}
+	}
+	debug.Log("Generated luminance-based ASCII frame: %dx%d", frameWidth, frameHeight)
+
+	debug.SaveFrameAsImage(f, "03_luminance_ascii")
+
+	if opts.DisableEdges {
+		debug.Log("Edge detection disabled")
+	} else {
+		// Step 2: Apply Difference of Gaussians to enhance edges for detection
+		debug.Log("Step 2: Applying Difference of Gaussians (sigma1=0.5, sigma2=1.5)")
+		dogImage := imageio.DifferenceOfGaussians(resized, 0.5, 1.5)
+		debug.SaveImage(dogImage, "04_dog_filtered")
+
+		// Step 3: Detect edges on DoG-filtered image
+		debug.Log("Step 3: Detecting edges with Sobel filter")
+		edges := edge.Sobel(dogImage)
+
+		edgeCount := 0
+
+		// Step 4: Replace edge positions with edge characters
+		debug.Log("Step 4: Applying edges with cutoff threshold: %.2f", opts.EdgeCutoff)
+		for y := 0; y < frameHeight; y++ {
+			for x := 0; x < frameWidth; x++ {
+				edgeInfo := edges[y][x]
+				if edgeInfo.Strength > opts.EdgeCutoff && !f.IsTransparent(x, y) {
+					f.Set(x, y, edge.EdgeChar(edgeInfo.Direction))
+					edgeCount++
+				}
+			}
+		}
+		if frameWidth > 0 && frameHeight > 0 {
+			debug.Log("Applied %d edge characters (%.2f%% of pixels)", edgeCount, float64(edgeCount)*100.0/float64(frameWidth*frameHeight))
+		}
+
+		debug.SaveFrameAsImage(f, "05_final_with_edges")
+	}
+
+	if opts.Palette != nil && opts.Color {
+		debug.Log("Quantizing colors to palette %s (dither: %v)", opts.Palette.Name, opts.Dither)
+		palette.QuantizeFrame(f, opts.Palette, opts.Dither)
+	}
+
+	return f
+}
+
+func (o Options) cellAspect() float64 {
+	if o.CellAspect <= 0 {
+		return imageio.CharAspectRatio
+	}
+	return o.CellAspect
+}
+
+// sameBounds reports whether all images have the same bounds.
+func sameBounds(images []image.Image) bool {
+	for _, img := range images[1:] {
+		if img.Bounds() != images[0].Bounds() {
+			return false
+		}
+	}
+	return true
+}
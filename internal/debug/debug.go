@@ -2,8 +2,9 @@ package debug
 
 import (
 	"fmt"
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/raster"
 	"image"
-	"image/color"
 	"image/png"
 	"os"
 	"path/filepath"
@@ -81,32 +82,15 @@ func SaveImage(img image.Image, name string) {
 	Log("Saved debug image: %s", fullPath)
 }
 
-func SaveFrameAsImage(frame [][]rune, name string) {
+// SaveFrameAsImage rasterises an ASCII frame with the bitmap font so the
+// intermediate result can be inspected as it would look in a terminal.
+func SaveFrameAsImage(f *frame.Frame, name string) {
 	if !IsEnabled() {
 		return
 	}
-	
-	height := len(frame)
-	if height == 0 {
-		return
-	}
-	width := len(frame[0])
-	
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-	
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			ch := frame[y][x]
-			var gray uint8
-			if ch == 0 {
-				gray = 0
-			} else {
-				gray = uint8(int(ch) % 256)
-			}
-			img.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
-		}
-	}
-	
+
+	img := raster.Render(f, raster.Options{Color: f.Colors != nil})
+
 	globalDebugger.stepCount++
 	filename := fmt.Sprintf("step_%02d_%s.png", globalDebugger.stepCount, name)
 	
@@ -2,14 +2,27 @@ package frame
 
 import (
 	"image/color"
+	"time"
 )
 
+// DefaultDelay is the duration of animation frames without timing
+// information.
+const DefaultDelay = 100 * time.Millisecond
+
 // Frame represents a rendered frame of ASCII characters.
 type Frame struct {
 	Width  int
 	Height int
 	Cells  [][]rune
 	Colors [][]color.Color
+
+	// Backgrounds holds optional per-cell background colours.
+	Backgrounds [][]color.Color
+
+	// Transparent marks cells that show the terminal's own background:
+	// they have no glyph and no colour. It stays nil until a cell is made
+	// transparent.
+	Transparent [][]bool
 }
 
 func New(width, height int) *Frame {
@@ -47,9 +60,34 @@ func (f *Frame) GetColor(x, y int) color.Color {
 	return nil
 }
 
+func (f *Frame) EnableBackgrounds() {
+	if f.Backgrounds == nil {
+		f.Backgrounds = make([][]color.Color, f.Height)
+		for i := range f.Backgrounds {
+			f.Backgrounds[i] = make([]color.Color, f.Width)
+		}
+	}
+}
+
+func (f *Frame) SetBackground(x, y int, c color.Color) {
+	if f.Backgrounds != nil && x >= 0 && x < f.Width && y >= 0 && y < f.Height {
+		f.Backgrounds[y][x] = c
+	}
+}
+
+func (f *Frame) GetBackground(x, y int) color.Color {
+	if f.Backgrounds != nil && x >= 0 && x < f.Width && y >= 0 && y < f.Height {
+		return f.Backgrounds[y][x]
+	}
+	return nil
+}
+
 func (f *Frame) Set(x, y int, ch rune) {
 	if x >= 0 && x < f.Width && y >= 0 && y < f.Height {
 		f.Cells[y][x] = ch
+		if f.Transparent != nil {
+			f.Transparent[y][x] = false
+		}
 	}
 }
 
@@ -59,3 +97,56 @@ func (f *Frame) Get(x, y int) rune {
 	}
 	return ' '
 }
+
+// SetTransparent makes the cell at x, y transparent and blanks its glyph.
+// Setting a glyph with Set makes the cell opaque again.
+func (f *Frame) SetTransparent(x, y int) {
+	if x >= 0 && x < f.Width && y >= 0 && y < f.Height {
+		if f.Transparent == nil {
+			f.Transparent = make([][]bool, f.Height)
+			for i := range f.Transparent {
+				f.Transparent[i] = make([]bool, f.Width)
+			}
+		}
+		f.Cells[y][x] = ' '
+		f.Transparent[y][x] = true
+	}
+}
+
+// IsTransparent reports whether the cell at x, y is transparent.
+func (f *Frame) IsTransparent(x, y int) bool {
+	if f.Transparent != nil && x >= 0 && x < f.Width && y >= 0 && y < f.Height {
+		return f.Transparent[y][x]
+	}
+	return false
+}
+
+// Blit copies src onto f with its top left corner at x, y, clipped to f.
+// Colours, backgrounds and transparency are copied when src has them.
+func (f *Frame) Blit(src *Frame, x, y int) {
+	if src.Colors != nil {
+		f.EnableColors()
+	}
+	if src.Backgrounds != nil {
+		f.EnableBackgrounds()
+	}
+	for sy := 0; sy < src.Height; sy++ {
+		for sx := 0; sx < src.Width; sx++ {
+			if src.IsTransparent(sx, sy) {
+				f.SetTransparent(x+sx, y+sy)
+			} else {
+				f.Set(x+sx, y+sy, src.Cells[sy][sx])
+			}
+			f.SetColor(x+sx, y+sy, src.GetColor(sx, sy))
+			f.SetBackground(x+sx, y+sy, src.GetBackground(sx, sy))
+		}
+	}
+}
+
+// SetText writes s into row y starting at column x, clipped to the frame.
+func (f *Frame) SetText(x, y int, s string) {
+	for _, ch := range s {
+		f.Set(x, y, ch)
+		x++
+	}
+}
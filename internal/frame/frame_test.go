@@ -0,0 +1,69 @@
+package frame
+
+import "testing"
+
+func TestTransparent(t *testing.T) {
+	f := New(3, 1)
+	if f.IsTransparent(0, 0) {
+		t.Fatal("unset cell is transparent")
+	}
+
+	f.Set(0, 0, '#')
+	f.SetTransparent(1, 0)
+	f.SetTransparent(2, 0)
+	f.Set(2, 0, '@')
+	f.SetTransparent(5, 5) // out of range
+
+	tests := []struct {
+		x           int
+		ch          rune
+		transparent bool
+	}{
+		{0, '#', false},
+		{1, ' ', true},
+		{2, '@', false},
+	}
+	for _, tt := range tests {
+		if got := f.Get(tt.x, 0); got != tt.ch {
+			t.Errorf("Get(%d, 0) = %q, want %q", tt.x, got, tt.ch)
+		}
+		if got := f.IsTransparent(tt.x, 0); got != tt.transparent {
+			t.Errorf("IsTransparent(%d, 0) = %v, want %v", tt.x, got, tt.transparent)
+		}
+	}
+	if f.IsTransparent(-1, 0) {
+		t.Error("out of range cell is transparent")
+	}
+}
+
+func TestBlit(t *testing.T) {
+	src := New(2, 2)
+	src.Set(0, 0, 'a')
+	src.SetTransparent(1, 0)
+	src.Set(0, 1, 'c')
+	src.Set(1, 1, 'd')
+
+	dst := New(3, 2)
+	for y := 0; y < dst.Height; y++ {
+		for x := 0; x < dst.Width; x++ {
+			dst.Set(x, y, '.')
+		}
+	}
+	dst.SetTransparent(0, 1)
+	dst.Blit(src, 1, 0)
+
+	want := []string{".a ", " cd"}
+	for y, line := range want {
+		for x, ch := range line {
+			if got := dst.Get(x, y); got != ch {
+				t.Errorf("Get(%d, %d) = %q, want %q", x, y, got, ch)
+			}
+		}
+	}
+	if !dst.IsTransparent(2, 0) {
+		t.Error("transparent source cell not copied")
+	}
+	if !dst.IsTransparent(0, 1) || dst.IsTransparent(1, 1) {
+		t.Error("cells outside the transparent source cell changed transparency")
+	}
+}
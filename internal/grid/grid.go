@@ -0,0 +1,82 @@
+package grid
+
+import (
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+// Options configures a grid layout.
+type Options struct {
+	// Columns is the number of tiles per row.
+	Columns int
+
+	// TileWidth is the width of every grid cell in characters.
+	TileWidth int
+
+	// Gutter is the space between tiles, in characters horizontally and
+	// lines vertically.
+	Gutter int
+}
+
+// Columns returns how many tiles of tileWidth fit side by side in width
+// characters, at least one and no more than count.
+func Columns(width, tileWidth, gutter, count int) int {
+	columns := (width + gutter) / (tileWidth + gutter)
+	return max(1, min(columns, count))
+}
+
+// TileWidth returns the widest tile that fits columns tiles into width
+// characters.
+func TileWidth(width, columns, gutter int) int {
+	return max(1, (width-gutter*(columns-1))/columns)
+}
+
+// Compose lays the tiles out left to right, top to bottom. Each tile is
+// centred horizontally in its grid cell and aligned to the top of its
+// row. If captions is not nil, captions[i] is written centred under
+// tile i, shortened to the cell width.
+func Compose(tiles []*frame.Frame, captions []string, opts Options) *frame.Frame {
+	columns := max(1, opts.Columns)
+	rows := (len(tiles) + columns - 1) / columns
+
+	captionHeight := 0
+	if captions != nil {
+		captionHeight = 1
+	}
+
+	// Each row is as tall as its tallest tile.
+	rowHeights := make([]int, rows)
+	for i, tile := range tiles {
+		rowHeights[i/columns] = max(rowHeights[i/columns], tile.Height)
+	}
+
+	width := columns*opts.TileWidth + (columns-1)*opts.Gutter
+	height := 0
+	for _, h := range rowHeights {
+		height += h + captionHeight
+	}
+	height += max(0, rows-1) * opts.Gutter
+
+	f := frame.New(width, height)
+	y := 0
+	for row := 0; row < rows; row++ {
+		for col := 0; col < columns; col++ {
+			i := row*columns + col
+			if i >= len(tiles) {
+				break
+			}
+			x := col * (opts.TileWidth + opts.Gutter)
+			tile := tiles[i]
+			f.Blit(tile, x+(opts.TileWidth-tile.Width)/2, y)
+
+			if captions != nil {
+				caption := []rune(captions[i])
+				if len(caption) > opts.TileWidth {
+					caption = append(caption[:max(0, opts.TileWidth-1)], '…')
+				}
+				f.SetText(x+(opts.TileWidth-len(caption))/2, y+rowHeights[row], string(caption))
+			}
+		}
+		y += rowHeights[row] + captionHeight + opts.Gutter
+	}
+	return f
+}
@@ -0,0 +1,120 @@
+package grid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+func tile(ch rune, width, height int) *frame.Frame {
+	f := frame.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			f.Set(x, y, ch)
+		}
+	}
+	return f
+}
+
+// lines returns the frame's rows with unset cells shown as '.'.
+func lines(f *frame.Frame) []string {
+	out := make([]string, f.Height)
+	for y := range out {
+		var b strings.Builder
+		for x := 0; x < f.Width; x++ {
+			ch := f.Get(x, y)
+			if ch == 0 {
+				ch = '.'
+			}
+			b.WriteRune(ch)
+		}
+		out[y] = b.String()
+	}
+	return out
+}
+
+func TestCompose(t *testing.T) {
+	tests := []struct {
+		name     string
+		tiles    []*frame.Frame
+		captions []string
+		opts     Options
+		want     []string
+	}{
+		{
+			name:  "one row",
+			tiles: []*frame.Frame{tile('a', 2, 1), tile('b', 2, 1)},
+			opts:  Options{Columns: 2, TileWidth: 2, Gutter: 1},
+			want:  []string{"aa.bb"},
+		},
+		{
+			name:  "wraps and centres",
+			tiles: []*frame.Frame{tile('a', 3, 2), tile('b', 1, 1), tile('c', 3, 1)},
+			opts:  Options{Columns: 2, TileWidth: 3, Gutter: 1},
+			want: []string{
+				"aaa..b.",
+				"aaa....",
+				".......",
+				"ccc....",
+			},
+		},
+		{
+			name:     "captions",
+			tiles:    []*frame.Frame{tile('a', 4, 1), tile('b', 4, 1)},
+			captions: []string{"x", "long name"},
+			opts:     Options{Columns: 2, TileWidth: 4, Gutter: 0},
+			want: []string{
+				"aaaabbbb",
+				".x..lon…",
+			},
+		},
+		{
+			name:  "fewer tiles than columns",
+			tiles: []*frame.Frame{tile('a', 1, 1)},
+			opts:  Options{Columns: 3, TileWidth: 1, Gutter: 1},
+			want:  []string{"a...."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lines(Compose(tt.tiles, tt.captions, tt.opts))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Compose() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		width, tileWidth, gutter, count int
+		want                            int
+	}{
+		{80, 20, 2, 10, 3},
+		{80, 19, 1, 10, 4},
+		{80, 20, 0, 2, 2},
+		{10, 20, 2, 5, 1},
+	}
+	for _, tt := range tests {
+		if got := Columns(tt.width, tt.tileWidth, tt.gutter, tt.count); got != tt.want {
+			t.Errorf("Columns(%d, %d, %d, %d) = %d, want %d", tt.width, tt.tileWidth, tt.gutter, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestTileWidth(t *testing.T) {
+	tests := []struct {
+		width, columns, gutter int
+		want                   int
+	}{
+		{80, 4, 2, 18},
+		{80, 1, 2, 80},
+		{3, 4, 2, 1},
+	}
+	for _, tt := range tests {
+		if got := TileWidth(tt.width, tt.columns, tt.gutter); got != tt.want {
+			t.Errorf("TileWidth(%d, %d, %d) = %d, want %d", tt.width, tt.columns, tt.gutter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+)
+
+// Contrast scales every colour channel away from mid-grey by factor:
+// 1 leaves the image unchanged, values above 1 increase contrast and
+// values between 0 and 1 flatten it. Alpha is preserved.
+func Contrast(img image.Image, factor float64) image.Image {
+	bounds := img.Bounds()
+
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = clampChannel((float64(i)-127.5)*factor + 127.5)
+	}
+
+	result := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			result.SetNRGBA(x, y, color.NRGBA{R: lut[c.R], G: lut[c.G], B: lut[c.B], A: c.A})
+		}
+	}
+	return result
+}
+
+func clampChannel(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
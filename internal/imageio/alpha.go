@@ -0,0 +1,64 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/kozmaoliver/asciify/internal/palette"
+)
+
+// Composite blends an image with transparency onto a solid matte colour,
+// producing a fully opaque image.
+func Composite(img image.Image, matte color.Color) image.Image {
+	bounds := img.Bounds()
+	mr, mg, mb, _ := matte.RGBA()
+
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			// RGBA() is alpha-premultiplied, so over-compositing is c + m*(1-a).
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			inv := 0xffff - a
+			result.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + mr*inv/0xffff) >> 8),
+				G: uint8((g + mg*inv/0xffff) >> 8),
+				B: uint8((b + mb*inv/0xffff) >> 8),
+				A: 255,
+			})
+		}
+	}
+	return result
+}
+
+// ChromaKey makes every pixel within tolerance (CIE76 delta E) of the key
+// colour fully transparent.
+func ChromaKey(img image.Image, key color.Color, tolerance float64) image.Image {
+	bounds := img.Bounds()
+	keyLab := palette.ToLab(key)
+	limit := tolerance * tolerance
+
+	result := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			if c.A > 0 && palette.ToLab(Opaque(c)).DistanceSq(keyLab) <= limit {
+				c.A = 0
+			}
+			result.SetNRGBA(x, y, c)
+		}
+	}
+	return result
+}
+
+// Opaque returns the colour without its alpha channel, undoing the
+// premultiplication so partially transparent pixels keep their hue.
+func Opaque(c color.Color) color.RGBA {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return color.RGBA{R: n.R, G: n.G, B: n.B, A: 255}
+}
+
+// Alpha returns the 8-bit alpha value of a colour.
+func Alpha(c color.Color) uint8 {
+	_, _, _, a := c.RGBA()
+	return uint8(a >> 8)
+}
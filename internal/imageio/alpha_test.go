@@ -0,0 +1,83 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestComposite(t *testing.T) {
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	tests := []struct {
+		name  string
+		pixel color.NRGBA
+		matte color.Color
+		want  color.RGBA
+	}{
+		{"opaque", color.NRGBA{0x10, 0x20, 0x30, 0xff}, white, color.RGBA{0x10, 0x20, 0x30, 0xff}},
+		{"transparent", color.NRGBA{0x10, 0x20, 0x30, 0x00}, white, white},
+		{"half", color.NRGBA{0x00, 0x00, 0x00, 0x80}, white, color.RGBA{0x7f, 0x7f, 0x7f, 0xff}},
+		{"half on black", color.NRGBA{0xff, 0x00, 0x00, 0x80}, color.Black, color.RGBA{0x80, 0x00, 0x00, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(5, 5, 7, 6))
+			img.SetNRGBA(5, 5, tt.pixel)
+			img.SetNRGBA(6, 5, tt.pixel)
+
+			out := Composite(img, tt.matte)
+			if b := out.Bounds(); b != image.Rect(0, 0, 2, 1) {
+				t.Fatalf("bounds = %v, want origin-based 2x1", b)
+			}
+			if got := color.RGBAModel.Convert(out.At(1, 0)); got != tt.want {
+				t.Errorf("pixel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChromaKey(t *testing.T) {
+	green := color.RGBA{0x00, 0xff, 0x00, 0xff}
+	tests := []struct {
+		name      string
+		pixel     color.NRGBA
+		tolerance float64
+		keyed     bool
+	}{
+		{"exact", color.NRGBA{0x00, 0xff, 0x00, 0xff}, 0, true},
+		{"close", color.NRGBA{0x10, 0xf0, 0x10, 0xff}, 30, true},
+		{"too far", color.NRGBA{0x10, 0xf0, 0x10, 0xff}, 1, false},
+		{"other hue", color.NRGBA{0xff, 0x00, 0x00, 0xff}, 30, false},
+		{"translucent key colour", color.NRGBA{0x00, 0xff, 0x00, 0x40}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+			img.SetNRGBA(0, 0, tt.pixel)
+
+			got := color.NRGBAModel.Convert(ChromaKey(img, green, tt.tolerance).At(0, 0)).(color.NRGBA)
+			if keyed := got.A == 0; keyed != tt.keyed {
+				t.Errorf("keyed = %v, want %v (pixel %v)", keyed, tt.keyed, got)
+			}
+			if !tt.keyed && got != tt.pixel {
+				t.Errorf("pixel changed to %v, want %v", got, tt.pixel)
+			}
+		})
+	}
+}
+
+func TestOpaque(t *testing.T) {
+	tests := []struct {
+		in   color.Color
+		want color.RGBA
+	}{
+		{color.NRGBA{0x80, 0x40, 0x20, 0x80}, color.RGBA{0x80, 0x40, 0x20, 0xff}},
+		{color.RGBA{0x40, 0x20, 0x10, 0x80}, color.RGBA{0x7f, 0x3f, 0x1f, 0xff}},
+		{color.Gray{0x33}, color.RGBA{0x33, 0x33, 0x33, 0xff}},
+	}
+	for _, tt := range tests {
+		if got := Opaque(tt.in); got != tt.want {
+			t.Errorf("Opaque(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
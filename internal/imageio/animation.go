@@ -0,0 +1,91 @@
+package imageio
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+// Animation is a sequence of images with per-frame display durations.
+type Animation struct {
+	Frames []image.Image
+	Delays []time.Duration
+}
+
+// LoadAnimation loads every frame of an animated GIF, from a file or
+// from standard input if the path is "-". Other formats load as a
+// single-frame animation.
+func LoadAnimation(path string, opts DecodeOptions) (*Animation, error) {
+	file, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return DecodeAnimation(file, opts)
+}
+
+// LoadSequence loads a list of still images as the frames of an animation,
+// each shown for the given delay.
+func LoadSequence(paths []string, delay time.Duration, opts DecodeOptions) (*Animation, error) {
+	anim := &Animation{}
+	for _, path := range paths {
+		img, err := LoadImage(path, opts)
+		if err != nil {
+			return nil, err
+		}
+		anim.Frames = append(anim.Frames, img)
+		anim.Delays = append(anim.Delays, delay)
+	}
+	return anim, nil
+}
+
+// gifAnimation composites the frames of a GIF onto a canvas, honouring
+// each frame's disposal method, so every resulting frame is a full image.
+func gifAnimation(g *gif.GIF) *Animation {
+	width, height := g.Config.Width, g.Config.Height
+	if width == 0 || height == 0 {
+		for _, img := range g.Image {
+			width = max(width, img.Bounds().Max.X)
+			height = max(height, img.Bounds().Max.Y)
+		}
+	}
+	bounds := image.Rect(0, 0, width, height)
+	canvas := image.NewRGBA(bounds)
+
+	anim := &Animation{}
+	for i, img := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+
+		snapshot := image.NewRGBA(bounds)
+		copy(snapshot.Pix, canvas.Pix)
+		anim.Frames = append(anim.Frames, snapshot)
+
+		delay := frame.DefaultDelay
+		if i < len(g.Delay) && g.Delay[i] > 0 {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		anim.Delays = append(anim.Delays, delay)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+	return anim
+}
@@ -0,0 +1,170 @@
+package imageio
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// AutocropMode selects how Autocrop trims an image.
+type AutocropMode string
+
+const (
+	AutocropOff AutocropMode = "off"
+
+	// AutocropBorders removes uniform borders such as solid backgrounds
+	// and letterboxing.
+	AutocropBorders AutocropMode = "borders"
+
+	// AutocropSaliency removes borders, then keeps the window with the
+	// most edge energy that matches the target aspect ratio.
+	AutocropSaliency AutocropMode = "saliency"
+)
+
+// ParseAutocropMode parses "off", "borders" or "saliency".
+func ParseAutocropMode(s string) (AutocropMode, error) {
+	switch m := AutocropMode(s); m {
+	case AutocropOff, AutocropBorders, AutocropSaliency:
+		return m, nil
+	}
+	return AutocropOff, fmt.Errorf("invalid autocrop mode %q (use off, borders or saliency)", s)
+}
+
+const (
+	// energySide bounds the image size the energy map is computed at.
+	energySide = 256
+
+	// borderEnergy is the fraction of the strongest edge below which a
+	// row or column counts as uniform.
+	borderEnergy = 0.05
+)
+
+// Autocrop trims the image according to mode. aspect is the width to
+// height ratio, in pixels, of the area the image will be shown in; it is
+// only used by AutocropSaliency.
+func Autocrop(img image.Image, mode AutocropMode, aspect float64) image.Image {
+	crop := AutocropRect([]image.Image{img}, mode, aspect)
+	if crop == img.Bounds() {
+		return img
+	}
+	return Crop(img, crop)
+}
+
+// AutocropRect returns the rectangle Autocrop trims to, shared by all the
+// frames, which must have the same bounds. The edge energy of the frames
+// is summed, so the rectangle keeps whatever moves through the animation
+// and every frame can be cropped identically. It returns the frames'
+// bounds when there is nothing to trim.
+func AutocropRect(frames []image.Image, mode AutocropMode, aspect float64) image.Rectangle {
+	bounds := frames[0].Bounds()
+	if mode != AutocropBorders && mode != AutocropSaliency {
+		return bounds
+	}
+
+	small, scaleX, scaleY := downscale(frames[0], energySide)
+	energy := EnergyMap(small)
+	for _, f := range frames[1:] {
+		small, _, _ := downscale(f, energySide)
+		for y, row := range EnergyMap(small) {
+			for x, e := range row {
+				energy[y][x] += e
+			}
+		}
+	}
+
+	r := contentBounds(energy)
+	if r.Empty() {
+		return bounds
+	}
+	if mode == AutocropSaliency && aspect > 0 {
+		r = salientWindow(energy, r, aspect)
+	}
+
+	// Back to source pixels, rounding outwards.
+	crop := image.Rect(
+		int(math.Floor(float64(r.Min.X)*scaleX)), int(math.Floor(float64(r.Min.Y)*scaleY)),
+		int(math.Ceil(float64(r.Max.X)*scaleX)), int(math.Ceil(float64(r.Max.Y)*scaleY)),
+	).Add(bounds.Min).Intersect(bounds)
+	if crop.Empty() {
+		return bounds
+	}
+	return crop
+}
+
+// contentBounds returns the smallest rectangle containing every row and
+// column whose peak energy is above the border threshold.
+func contentBounds(energy [][]float64) image.Rectangle {
+	peak := 0.0
+	for _, row := range energy {
+		for _, e := range row {
+			peak = math.Max(peak, e)
+		}
+	}
+	if peak == 0 {
+		return image.Rectangle{}
+	}
+	threshold := peak * borderEnergy
+
+	r := image.Rectangle{Min: image.Pt(math.MaxInt, math.MaxInt)}
+	for y, row := range energy {
+		for x, e := range row {
+			if e > threshold {
+				r.Min.X = min(r.Min.X, x)
+				r.Min.Y = min(r.Min.Y, y)
+				r.Max.X = max(r.Max.X, x+1)
+				r.Max.Y = max(r.Max.Y, y+1)
+			}
+		}
+	}
+	return r
+}
+
+// salientWindow returns the largest window of the given aspect ratio
+// inside r, slid along r's longer side to where the energy is highest.
+func salientWindow(energy [][]float64, r image.Rectangle, aspect float64) image.Rectangle {
+	w, h := r.Dx(), r.Dy()
+	if float64(w)/float64(h) > aspect {
+		// Too wide: slide a full-height window horizontally.
+		ww := max(1, int(math.Round(float64(h)*aspect)))
+		cols := make([]float64, w)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				cols[x-r.Min.X] += energy[y][x]
+			}
+		}
+		best := bestOffset(cols, ww)
+		return image.Rect(r.Min.X+best, r.Min.Y, r.Min.X+best+ww, r.Max.Y)
+	}
+
+	// Too tall: slide a full-width window vertically.
+	wh := max(1, int(math.Round(float64(w)/aspect)))
+	rows := make([]float64, h)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			rows[y-r.Min.Y] += energy[y][x]
+		}
+	}
+	best := bestOffset(rows, wh)
+	return image.Rect(r.Min.X, r.Min.Y+best, r.Max.X, r.Min.Y+best+wh)
+}
+
+// bestOffset returns the start of the run of n values with the largest
+// sum, preferring the most central one on ties.
+func bestOffset(values []float64, n int) int {
+	if n >= len(values) {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values[:n] {
+		sum += v
+	}
+	best, bestSum := 0, sum
+	centre := float64(len(values)-n) / 2
+	for i := 1; i+n <= len(values); i++ {
+		sum += values[i+n-1] - values[i-1]
+		if sum > bestSum || (sum == bestSum && math.Abs(float64(i)-centre) < math.Abs(float64(best)-centre)) {
+			best, bestSum = i, sum
+		}
+	}
+	return best
+}
@@ -0,0 +1,141 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+// canvas returns a mid-grey image with black squares at rects.
+func canvas(bounds image.Rectangle, rects ...image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, image.NewUniform(color.Gray{0x80}), image.Point{}, draw.Src)
+	for _, r := range rects {
+		draw.Draw(img, r, image.Black, image.Point{}, draw.Src)
+	}
+	return img
+}
+
+// checkered returns a grey image with a black and white checkerboard of
+// 2-pixel squares inside r, which has much more edge energy per pixel
+// than a solid square.
+func checkered(bounds, r image.Rectangle, others ...image.Rectangle) *image.RGBA {
+	img := canvas(bounds, others...)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if (x/2+y/2)%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+func TestAutocropRect(t *testing.T) {
+	square := image.Rect(40, 25, 60, 35)
+	offset := image.Pt(10, 20)
+
+	tests := []struct {
+		name   string
+		frames []image.Image
+		mode   AutocropMode
+		aspect float64
+		// The crop must contain want and stay within margin pixels of it.
+		want   image.Rectangle
+		margin int
+	}{
+		{
+			name:   "off",
+			frames: []image.Image{canvas(image.Rect(0, 0, 100, 60), square)},
+			mode:   AutocropOff,
+			want:   image.Rect(0, 0, 100, 60),
+		},
+		{
+			name:   "uniform",
+			frames: []image.Image{canvas(image.Rect(0, 0, 100, 60))},
+			mode:   AutocropBorders,
+			want:   image.Rect(0, 0, 100, 60),
+		},
+		{
+			name:   "borders",
+			frames: []image.Image{canvas(image.Rect(0, 0, 100, 60), square)},
+			mode:   AutocropBorders,
+			want:   square,
+			margin: 6,
+		},
+		{
+			name:   "offset bounds",
+			frames: []image.Image{canvas(image.Rect(0, 0, 100, 60).Add(offset), square.Add(offset))},
+			mode:   AutocropBorders,
+			want:   square.Add(offset),
+			margin: 6,
+		},
+		{
+			name:   "downscaled",
+			frames: []image.Image{canvas(image.Rect(0, 0, 1000, 600), image.Rect(400, 250, 600, 350))},
+			mode:   AutocropBorders,
+			want:   image.Rect(400, 250, 600, 350),
+			margin: 24,
+		},
+		{
+			// Frames share one rectangle that covers both squares.
+			name: "animation",
+			frames: []image.Image{
+				canvas(image.Rect(0, 0, 100, 60), image.Rect(10, 10, 20, 20)),
+				canvas(image.Rect(0, 0, 100, 60), image.Rect(70, 40, 80, 50)),
+			},
+			mode:   AutocropBorders,
+			want:   image.Rect(10, 10, 80, 50),
+			margin: 6,
+		},
+		{
+			// The square window slides to the busier, checkered area.
+			name:   "saliency",
+			frames: []image.Image{checkered(image.Rect(0, 0, 200, 60), image.Rect(150, 10, 190, 50), image.Rect(10, 10, 50, 50))},
+			mode:   AutocropSaliency,
+			aspect: 1,
+			want:   image.Rect(150, 10, 190, 50),
+			margin: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AutocropRect(tt.frames, tt.mode, tt.aspect)
+			limit := tt.want.Inset(-tt.margin).Intersect(tt.frames[0].Bounds())
+			if !tt.want.In(got) || !got.In(limit) {
+				t.Errorf("AutocropRect() = %v, want %v within %d pixels", got, tt.want, tt.margin)
+			}
+			if tt.aspect > 0 {
+				if aspect := float64(got.Dx()) / float64(got.Dy()); math.Abs(aspect-tt.aspect) > 0.1 {
+					t.Errorf("AutocropRect() aspect = %.2f, want %.2f", aspect, tt.aspect)
+				}
+			}
+		})
+	}
+}
+
+func TestAutocrop(t *testing.T) {
+	img := canvas(image.Rect(0, 0, 100, 60), image.Rect(40, 25, 60, 35))
+	if got := Autocrop(img, AutocropOff, 0); got != image.Image(img) {
+		t.Error("Autocrop(off) did not return the image itself")
+	}
+	got := Autocrop(img, AutocropBorders, 0)
+	if got.Bounds() != AutocropRect([]image.Image{img}, AutocropBorders, 0) {
+		t.Errorf("Autocrop() bounds = %v, want the AutocropRect", got.Bounds())
+	}
+}
+
+func TestParseAutocropMode(t *testing.T) {
+	for _, s := range []string{"off", "borders", "saliency"} {
+		if m, err := ParseAutocropMode(s); err != nil || string(m) != s {
+			t.Errorf("ParseAutocropMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := ParseAutocropMode("edges"); err == nil {
+		t.Error("ParseAutocropMode(\"edges\") succeeded")
+	}
+}
@@ -0,0 +1,211 @@
+package imageio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math/bits"
+)
+
+func init() {
+	image.RegisterFormat("bmp", "BM", decodeBMP, decodeBMPConfig)
+}
+
+// BMP compression methods.
+const (
+	bmpRGB            = 0
+	bmpBitfields      = 3
+	bmpAlphaBitfields = 6
+)
+
+// bmpHeader is the part of a Windows bitmap header needed for decoding.
+type bmpHeader struct {
+	width, height int
+	topDown       bool
+	bpp           int
+	masks         [4]uint32 // red, green, blue, alpha
+	palette       []color.NRGBA
+	pixels        int // offset of the pixel array
+}
+
+func decodeBMPConfig(r io.Reader) (image.Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	h, err := parseBMPHeader(data)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.NRGBAModel, Width: h.width, Height: h.height}, nil
+}
+
+// decodeBMP decodes uncompressed and bitfield-encoded Windows bitmaps of
+// 1, 4, 8, 16, 24 and 32 bits per pixel, including 32-bit images with an
+// alpha channel. RLE compression is not supported.
+func decodeBMP(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	h, err := parseBMPHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	stride := (h.width*h.bpp + 31) / 32 * 4
+	if h.pixels < 0 || h.pixels > len(data) || len(data)-h.pixels < stride*h.height {
+		return nil, fmt.Errorf("bmp: %w", errTruncated)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, h.width, h.height))
+	hasAlpha := false
+	for row := 0; row < h.height; row++ {
+		y := h.height - 1 - row
+		if h.topDown {
+			y = row
+		}
+		line := data[h.pixels+row*stride : h.pixels+(row+1)*stride]
+
+		for x := 0; x < h.width; x++ {
+			var c color.NRGBA
+			switch h.bpp {
+			case 1, 4, 8:
+				bit := x * h.bpp
+				idx := int(line[bit/8]>>(8-h.bpp-bit%8)) & (1<<h.bpp - 1)
+				if idx < len(h.palette) {
+					c = h.palette[idx]
+				} else {
+					c = color.NRGBA{A: 0xff}
+				}
+			case 16:
+				c = bmpBitfield(uint32(binary.LittleEndian.Uint16(line[x*2:])), h.masks)
+			case 24:
+				c = color.NRGBA{R: line[x*3+2], G: line[x*3+1], B: line[x*3], A: 0xff}
+			case 32:
+				c = bmpBitfield(binary.LittleEndian.Uint32(line[x*4:]), h.masks)
+			}
+			if c.A != 0 {
+				hasAlpha = true
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	// Many writers leave the alpha byte of 32-bit images zero; treat an
+	// entirely transparent image as having no alpha channel.
+	if !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img, nil
+}
+
+func parseBMPHeader(data []byte) (*bmpHeader, error) {
+	if len(data) < 18 || data[0] != 'B' || data[1] != 'M' {
+		return nil, fmt.Errorf("bmp: not a bitmap")
+	}
+	h := &bmpHeader{pixels: int(binary.LittleEndian.Uint32(data[10:]))}
+	infoSize := int(binary.LittleEndian.Uint32(data[14:]))
+	if len(data) < 14+infoSize {
+		return nil, fmt.Errorf("bmp: %w", errTruncated)
+	}
+	info := data[14 : 14+infoSize]
+
+	paletteEntry := 4
+	compression := bmpRGB
+	colorsUsed := 0
+	switch {
+	case infoSize == 12:
+		// OS/2 BITMAPCOREHEADER with 16-bit dimensions and RGB triples.
+		h.width = int(binary.LittleEndian.Uint16(info[4:]))
+		h.height = int(binary.LittleEndian.Uint16(info[6:]))
+		h.bpp = int(binary.LittleEndian.Uint16(info[10:]))
+		paletteEntry = 3
+	case infoSize >= 40:
+		h.width = int(int32(binary.LittleEndian.Uint32(info[4:])))
+		h.height = int(int32(binary.LittleEndian.Uint32(info[8:])))
+		h.bpp = int(binary.LittleEndian.Uint16(info[14:]))
+		compression = int(binary.LittleEndian.Uint32(info[16:]))
+		colorsUsed = int(binary.LittleEndian.Uint32(info[32:]))
+	default:
+		return nil, fmt.Errorf("bmp: unsupported header size %d", infoSize)
+	}
+	if h.height < 0 {
+		h.height = -h.height
+		h.topDown = true
+	}
+	if err := checkDimensions("bmp", h.width, h.height); err != nil {
+		return nil, err
+	}
+
+	// Default channel layouts; bitfield images override them.
+	switch h.bpp {
+	case 16:
+		h.masks = [4]uint32{0x7c00, 0x03e0, 0x001f, 0}
+	case 32:
+		h.masks = [4]uint32{0x00ff0000, 0x0000ff00, 0x000000ff, 0xff000000}
+	}
+
+	switch compression {
+	case bmpRGB:
+	case bmpBitfields, bmpAlphaBitfields:
+		n := 3
+		if compression == bmpAlphaBitfields {
+			n = 4
+		}
+		// Masks are part of V4/V5 headers, or follow a 40-byte header.
+		masks := data[14+40:]
+		if infoSize >= 56 {
+			n = 4
+		}
+		if len(masks) < n*4 {
+			return nil, fmt.Errorf("bmp: %w", errTruncated)
+		}
+		h.masks = [4]uint32{}
+		for i := 0; i < n; i++ {
+			h.masks[i] = binary.LittleEndian.Uint32(masks[i*4:])
+		}
+	default:
+		return nil, fmt.Errorf("bmp: unsupported compression %d", compression)
+	}
+
+	switch h.bpp {
+	case 1, 4, 8:
+		count := colorsUsed
+		if count == 0 || count > 1<<h.bpp {
+			count = 1 << h.bpp
+		}
+		table := data[14+infoSize:]
+		for i := 0; i < count && (i+1)*paletteEntry <= len(table); i++ {
+			e := table[i*paletteEntry:]
+			h.palette = append(h.palette, color.NRGBA{R: e[2], G: e[1], B: e[0], A: 0xff})
+		}
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("bmp: unsupported bit depth %d", h.bpp)
+	}
+	return h, nil
+}
+
+// bmpBitfield extracts the channels of a pixel with the given masks,
+// scaling each to 8 bits. Without an alpha mask the pixel is opaque.
+func bmpBitfield(px uint32, masks [4]uint32) color.NRGBA {
+	var ch [4]uint8
+	for i, m := range masks {
+		if m == 0 {
+			continue
+		}
+		shift := bits.TrailingZeros32(m)
+		width := bits.OnesCount32(m)
+		v := uint64((px & m) >> shift)
+		ch[i] = uint8(v * 255 / (1<<width - 1))
+	}
+	if masks[3] == 0 {
+		ch[3] = 0xff
+	}
+	return color.NRGBA{R: ch[0], G: ch[1], B: ch[2], A: ch[3]}
+}
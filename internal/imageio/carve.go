@@ -0,0 +1,185 @@
+package imageio
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/kozmaoliver/asciify/internal/edge"
+)
+
+// FitMode selects how an image is fitted to the terminal's aspect ratio.
+type FitMode string
+
+const (
+	// FitContain scales the image to fit, leaving the remaining space empty.
+	FitContain FitMode = "contain"
+
+	// FitCarve first removes low-energy seams until the image has the
+	// terminal's aspect ratio, keeping the subject large.
+	FitCarve FitMode = "carve"
+)
+
+// ParseFitMode parses "contain" or "carve".
+func ParseFitMode(s string) (FitMode, error) {
+	switch m := FitMode(s); m {
+	case FitContain, FitCarve:
+		return m, nil
+	}
+	return FitContain, fmt.Errorf("invalid fit mode %q (use contain or carve)", s)
+}
+
+const (
+	// maxCarve is the largest fraction of the width or height that seam
+	// carving removes; beyond that images distort badly, so the rest of
+	// the difference is left to letterboxing.
+	maxCarve = 0.5
+
+	// carveEnergyInterval is how many seams are removed between energy
+	// map recomputations. In between, the energy of removed pixels is
+	// dropped along with them.
+	carveEnergyInterval = 16
+)
+
+// Carve removes the lowest-energy seams, by Sobel magnitude, until the
+// image's width to height ratio is aspect. Images larger than maxSide
+// are downscaled first, since seams are only needed at the resolution
+// the result will be shown at.
+func Carve(img image.Image, aspect float64, maxSide int) image.Image {
+	return CarveFrames([]image.Image{img}, aspect, maxSide)[0]
+}
+
+// CarveFrames carves the frames of an animation, which must have the same
+// bounds, along the same seams. The seams follow the summed energy of all
+// frames, so they avoid anything that moves through the animation and the
+// carved frames do not wobble.
+func CarveFrames(frames []image.Image, aspect float64, maxSide int) []image.Image {
+	small := make([]*image.RGBA, len(frames))
+	for i, f := range frames {
+		small[i], _, _ = downscale(f, maxSide)
+	}
+	w, h := small[0].Bounds().Dx(), small[0].Bounds().Dy()
+
+	if float64(w)/float64(h) > aspect {
+		seams := min(w-int(math.Round(float64(h)*aspect)), int(float64(w)*maxCarve))
+		return carveColumns(small, max(seams, 0))
+	}
+
+	seams := min(h-int(math.Round(float64(w)/aspect)), int(float64(h)*maxCarve))
+	// Horizontal seams are vertical seams of the transposed frames.
+	for i, img := range small {
+		small[i] = rgbaImage(Transpose(img))
+	}
+	carved := carveColumns(small, max(seams, 0))
+	for i, img := range carved {
+		carved[i] = Transpose(img)
+	}
+	return carved
+}
+
+// carveColumns removes the same n vertical seams from every frame.
+func carveColumns(frames []*image.RGBA, n int) []image.Image {
+	w, h := frames[0].Bounds().Dx(), frames[0].Bounds().Dy()
+	pixels := make([][][]color.RGBA, len(frames))
+	for i, img := range frames {
+		pixels[i] = make([][]color.RGBA, h)
+		for y := range pixels[i] {
+			pixels[i][y] = make([]color.RGBA, w)
+			for x := range pixels[i][y] {
+				pixels[i][y][x] = img.RGBAAt(x, y)
+			}
+		}
+	}
+
+	var energy [][]float64
+	for s := 0; s < n; s++ {
+		if s%carveEnergyInterval == 0 {
+			energy = sobelEnergy(pixels[0])
+			for _, p := range pixels[1:] {
+				for y, row := range sobelEnergy(p) {
+					for x, e := range row {
+						energy[y][x] += e
+					}
+				}
+			}
+		}
+		seam := findSeam(energy)
+		for y, x := range seam {
+			for _, p := range pixels {
+				p[y] = append(p[y][:x], p[y][x+1:]...)
+			}
+			energy[y] = append(energy[y][:x], energy[y][x+1:]...)
+		}
+	}
+
+	carved := make([]image.Image, len(frames))
+	for i, p := range pixels {
+		out := image.NewRGBA(image.Rect(0, 0, w-n, h))
+		for y, row := range p {
+			for x, c := range row {
+				out.SetRGBA(x, y, c)
+			}
+		}
+		carved[i] = out
+	}
+	return carved
+}
+
+// sobelEnergy returns the Sobel gradient magnitude of every pixel.
+func sobelEnergy(pixels [][]color.RGBA) [][]float64 {
+	img := image.NewRGBA(image.Rect(0, 0, len(pixels[0]), len(pixels)))
+	for y, row := range pixels {
+		for x, c := range row {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	edges := edge.Sobel(img)
+	energy := make([][]float64, len(edges))
+	for y, row := range edges {
+		energy[y] = make([]float64, len(row))
+		for x, e := range row {
+			energy[y][x] = e.Strength
+		}
+	}
+	return energy
+}
+
+// findSeam returns, for every row, the column of the connected top to
+// bottom path with the least total energy.
+func findSeam(energy [][]float64) []int {
+	h, w := len(energy), len(energy[0])
+	cost := make([][]float64, h)
+	cost[0] = append([]float64(nil), energy[0]...)
+	for y := 1; y < h; y++ {
+		cost[y] = make([]float64, w)
+		for x := 0; x < w; x++ {
+			best := cost[y-1][x]
+			if x > 0 {
+				best = math.Min(best, cost[y-1][x-1])
+			}
+			if x < w-1 {
+				best = math.Min(best, cost[y-1][x+1])
+			}
+			cost[y][x] = energy[y][x] + best
+		}
+	}
+
+	seam := make([]int, h)
+	for x := 1; x < w; x++ {
+		if cost[h-1][x] < cost[h-1][seam[h-1]] {
+			seam[h-1] = x
+		}
+	}
+	for y := h - 2; y >= 0; y-- {
+		x := seam[y+1]
+		seam[y] = x
+		if x > 0 && cost[y][x-1] < cost[y][seam[y]] {
+			seam[y] = x - 1
+		}
+		if x < w-1 && cost[y][x+1] < cost[y][seam[y]] {
+			seam[y] = x + 1
+		}
+	}
+	return seam
+}
@@ -0,0 +1,123 @@
+package imageio
+
+import (
+	"image"
+	"testing"
+)
+
+// barCount returns, for every row, the number of black pixels.
+func barCount(img image.Image) []int {
+	b := img.Bounds()
+	counts := make([]int, b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r == 0 {
+				counts[y-b.Min.Y]++
+			}
+		}
+	}
+	return counts
+}
+
+func TestCarveFrames(t *testing.T) {
+	// Bars are two pixels wide, so every bar pixel is on an edge.
+	bar := func(x int) image.Rectangle { return image.Rect(x, 0, x+2, 100) }
+
+	tests := []struct {
+		name    string
+		frames  []image.Image
+		aspect  float64
+		maxSide int
+		want    image.Point
+	}{
+		{
+			name:    "narrower",
+			frames:  []image.Image{canvas(image.Rect(0, 0, 40, 10), bar(30))},
+			aspect:  2,
+			maxSide: 100,
+			want:    image.Pt(20, 10),
+		},
+		{
+			name:    "shorter",
+			frames:  []image.Image{canvas(image.Rect(0, 0, 10, 40), image.Rect(0, 30, 10, 32))},
+			aspect:  0.5,
+			maxSide: 100,
+			want:    image.Pt(10, 20),
+		},
+		{
+			name:    "at most half",
+			frames:  []image.Image{canvas(image.Rect(0, 0, 100, 10))},
+			aspect:  1,
+			maxSide: 100,
+			want:    image.Pt(50, 10),
+		},
+		{
+			name:    "already fits",
+			frames:  []image.Image{canvas(image.Rect(0, 0, 20, 10), bar(5))},
+			aspect:  2,
+			maxSide: 100,
+			want:    image.Pt(20, 10),
+		},
+		{
+			name:    "downscaled first",
+			frames:  []image.Image{canvas(image.Rect(0, 0, 400, 100))},
+			aspect:  2,
+			maxSide: 100,
+			want:    image.Pt(50, 25),
+		},
+		{
+			// The seams follow the energy of both frames, so neither bar
+			// is cut.
+			name: "animation",
+			frames: []image.Image{
+				canvas(image.Rect(0, 0, 60, 10), bar(5)),
+				canvas(image.Rect(0, 0, 60, 10), bar(45)),
+			},
+			aspect:  3,
+			maxSide: 100,
+			want:    image.Pt(30, 10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			carved := CarveFrames(tt.frames, tt.aspect, tt.maxSide)
+			if len(carved) != len(tt.frames) {
+				t.Fatalf("got %d frames, want %d", len(carved), len(tt.frames))
+			}
+			for i, img := range carved {
+				if got := img.Bounds().Size(); got != tt.want {
+					t.Errorf("frame %d is %v, want %v", i, got, tt.want)
+				}
+				// Low-energy seams are removed first, so the bars survive.
+				if img.Bounds().Dy() != tt.frames[i].Bounds().Dy() {
+					continue
+				}
+				want := barCount(tt.frames[i])
+				for y, n := range barCount(img) {
+					if n != want[y] {
+						t.Errorf("frame %d row %d has %d bar pixels, want %d", i, y, n, want[y])
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCarve(t *testing.T) {
+	img := canvas(image.Rect(0, 0, 40, 10))
+	if got := Carve(img, 2, 100).Bounds().Size(); got != image.Pt(20, 10) {
+		t.Errorf("Carve() is %v, want 20x10", got)
+	}
+}
+
+func TestParseFitMode(t *testing.T) {
+	for _, s := range []string{"contain", "carve"} {
+		if m, err := ParseFitMode(s); err != nil || string(m) != s {
+			t.Errorf("ParseFitMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := ParseFitMode("cover"); err == nil {
+		t.Error("ParseFitMode(\"cover\") succeeded")
+	}
+}
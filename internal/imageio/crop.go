@@ -0,0 +1,21 @@
+package imageio
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Crop returns the part of the image inside r, clipped to its bounds.
+// Images that support SubImage share their pixels with the result.
+func Crop(img image.Image, r image.Rectangle) image.Image {
+	r = r.Intersect(img.Bounds())
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(result, result.Bounds(), img, r.Min, draw.Src)
+	return result
+}
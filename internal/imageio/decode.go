@@ -0,0 +1,130 @@
+package imageio
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/debug"
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+// Stdin is the path that reads an image from standard input.
+const Stdin = "-"
+
+// DecodeOptions configures how images are decoded.
+type DecodeOptions struct {
+	// AutoOrient applies the EXIF orientation of JPEG images, so photos
+	// taken with a rotated camera display upright.
+	AutoOrient bool
+}
+
+// magic maps the leading bytes of image files to their format names.
+var magic = []struct {
+	prefix string
+	format string
+}{
+	{"\x89PNG\r\n\x1a\n", "png"},
+	{"\xff\xd8\xff", "jpeg"},
+	{"GIF87a", "gif"},
+	{"GIF89a", "gif"},
+	{"BM", "bmp"},
+	{"P1", "pbm"},
+	{"P4", "pbm"},
+	{"P2", "pgm"},
+	{"P5", "pgm"},
+	{"P3", "ppm"},
+	{"P6", "ppm"},
+	{"P7", "pam"},
+	{"qoif", "qoi"},
+	{"farbfeld", "farbfeld"},
+}
+
+// Sniff returns the format of an image from its first bytes, or "" if it
+// is not recognised.
+func Sniff(header []byte) string {
+	for _, m := range magic {
+		if bytes.HasPrefix(header, []byte(m.prefix)) {
+			return m.format
+		}
+	}
+	return ""
+}
+
+// Decode decodes a still image from r. The format is detected from its
+// content, so any reader works: a file, standard input, a network body
+// or bytes.NewReader over an in-memory image.
+//
+// JPEG images are turned upright according to their EXIF orientation
+// when opts.AutoOrient is set.
+func Decode(r io.Reader, opts DecodeOptions) (image.Image, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(8)
+	if Sniff(header) != "jpeg" {
+		img, _, err := image.Decode(br)
+		return img, err
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	exif, err := ReadEXIF(data)
+	if err != nil {
+		debug.Log("JPEG %dx%d: %v", img.Bounds().Dx(), img.Bounds().Dy(), err)
+		return img, nil
+	}
+	debug.Log("JPEG %dx%d: EXIF orientation %d, dimensions %dx%d, captured %q",
+		img.Bounds().Dx(), img.Bounds().Dy(), exif.Orientation, exif.Width, exif.Height, exif.DateTime)
+	if opts.AutoOrient && exif.Orientation != 1 {
+		img = Orient(img, exif.Orientation)
+	}
+	return img, nil
+}
+
+// DecodeAnimation decodes every frame of an animated GIF from r. Other
+// formats decode as a single-frame animation.
+func DecodeAnimation(r io.Reader, opts DecodeOptions) (*Animation, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(8)
+	if Sniff(header) != "gif" {
+		img, err := Decode(br, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{Frames: []image.Image{img}, Delays: []time.Duration{frame.DefaultDelay}}, nil
+	}
+
+	g, err := gif.DecodeAll(br)
+	if err != nil {
+		return nil, err
+	}
+	return gifAnimation(g), nil
+}
+
+// open opens a file, or standard input for Stdin.
+func open(path string) (io.ReadCloser, error) {
+	if path == Stdin {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// Name returns a short name for an image path to show in captions: its
+// base name, or "stdin".
+func Name(path string) string {
+	if path == Stdin {
+		return "stdin"
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,90 @@
+package imageio
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"\x89PNG\r\n\x1a\n....", "png"},
+		{"\xff\xd8\xff\xe0", "jpeg"},
+		{"GIF87a", "gif"},
+		{"GIF89a\x01\x00", "gif"},
+		{"GIF8", ""},
+		{"\x89PNG", ""},
+		{"not an image", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Sniff([]byte(tt.header)); got != tt.want {
+			t.Errorf("Sniff(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeAnimation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.RGBA{0xff, 0, 0, 0xff})
+
+	var pngData, jpegData, gifData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegData, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	g := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 4, 3), palette.Plan9))
+		g.Delay = append(g.Delay, 5*(i+1))
+	}
+	if err := gif.EncodeAll(&gifData, g); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		delays []time.Duration
+	}{
+		{"png", pngData.Bytes(), []time.Duration{frame.DefaultDelay}},
+		{"jpeg", jpegData.Bytes(), []time.Duration{frame.DefaultDelay}},
+		{"gif", gifData.Bytes(), []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim, err := DecodeAnimation(bytes.NewReader(tt.data), DecodeOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(anim.Frames) != len(tt.delays) {
+				t.Fatalf("got %d frames, want %d", len(anim.Frames), len(tt.delays))
+			}
+			for i, f := range anim.Frames {
+				if got := f.Bounds().Size(); got != image.Pt(4, 3) {
+					t.Errorf("frame %d is %v, want 4x3", i, got)
+				}
+				if anim.Delays[i] != tt.delays[i] {
+					t.Errorf("delay %d = %v, want %v", i, anim.Delays[i], tt.delays[i])
+				}
+			}
+		})
+	}
+
+	if _, err := DecodeAnimation(bytes.NewReader([]byte("not an image")), DecodeOptions{}); err == nil {
+		t.Error("DecodeAnimation of garbage succeeded")
+	}
+}
@@ -0,0 +1,60 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/kozmaoliver/asciify/internal/edge"
+)
+
+// EnergyMap returns the edge energy of every pixel: the Sobel magnitude
+// of the Difference of Gaussians, the same signal the converter uses to
+// place edge characters. Flat areas have (near) zero energy.
+func EnergyMap(img image.Image) [][]float64 {
+	edges := edge.Sobel(DifferenceOfGaussians(rgbaImage(img), 0.5, 1.5))
+	energy := make([][]float64, len(edges))
+	for y, row := range edges {
+		energy[y] = make([]float64, len(row))
+		for x, e := range row {
+			energy[y][x] = e.Strength
+		}
+	}
+	return energy
+}
+
+// downscale shrinks the image with a box filter so that neither side
+// exceeds maxSide, returning it with the horizontal and vertical factors
+// from the original size; they differ slightly because the output size is
+// rounded. Smaller images are returned as they are, with bounds starting
+// at 0,0.
+func downscale(img image.Image, maxSide int) (out *image.RGBA, scaleX, scaleY float64) {
+	in := rgbaImage(img)
+	w, h := in.Bounds().Dx(), in.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return in, 1, 1
+	}
+
+	scale := float64(max(w, h)) / float64(maxSide)
+	outW := max(1, int(float64(w)/scale))
+	outH := max(1, int(float64(h)/scale))
+	out = image.NewRGBA(image.Rect(0, 0, outW, outH))
+	for y := 0; y < outH; y++ {
+		y0, y1 := y*h/outH, max((y+1)*h/outH, y*h/outH+1)
+		for x := 0; x < outW; x++ {
+			x0, x1 := x*w/outW, max((x+1)*w/outW, x*w/outW+1)
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := in.RGBAAt(sx, sy)
+					r += int(c.R)
+					g += int(c.G)
+					b += int(c.B)
+					a += int(c.A)
+					n++
+				}
+			}
+			out.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)})
+		}
+	}
+	return out, float64(w) / float64(outW), float64(h) / float64(outH)
+}
@@ -0,0 +1,183 @@
+package imageio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"strings"
+)
+
+// EXIF and TIFF tags read by ReadEXIF.
+const (
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xa002
+	tagPixelYDimension  = 0xa003
+)
+
+var errNoEXIF = errors.New("no EXIF data")
+
+// EXIF is the basic metadata of a JPEG image.
+type EXIF struct {
+	// Orientation is the EXIF orientation, 1 to 8; 1 is upright.
+	Orientation int
+
+	// Width and Height are the pixel dimensions recorded in the EXIF
+	// data, or 0 if absent.
+	Width, Height int
+
+	// DateTime is the capture date, "YYYY:MM:DD HH:MM:SS", falling back
+	// to the modification date.
+	DateTime string
+}
+
+// ReadEXIF extracts the EXIF metadata from the APP1 segment of a JPEG
+// file. Only the tags in EXIF are read.
+func ReadEXIF(jpeg []byte) (*EXIF, error) {
+	tiff, err := findEXIF(jpeg)
+	if err != nil {
+		return nil, err
+	}
+	if len(tiff) < 8 {
+		return nil, errNoEXIF
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("exif: invalid byte order")
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return nil, errors.New("exif: invalid TIFF header")
+	}
+
+	exif := &EXIF{Orientation: 1}
+	var modified string
+	readIFD(tiff, order, order.Uint32(tiff[4:]), func(tag uint16, value []byte, count uint32, typ uint16) {
+		switch tag {
+		case tagOrientation:
+			if o := int(ifdInt(order, value, typ)); o >= 1 && o <= 8 {
+				exif.Orientation = o
+			}
+		case tagDateTime:
+			modified = ifdString(tiff, order, value, count)
+		case tagExifIFD:
+			readIFD(tiff, order, ifdInt(order, value, typ), func(tag uint16, value []byte, count uint32, typ uint16) {
+				switch tag {
+				case tagDateTimeOriginal:
+					exif.DateTime = ifdString(tiff, order, value, count)
+				case tagPixelXDimension:
+					exif.Width = int(ifdInt(order, value, typ))
+				case tagPixelYDimension:
+					exif.Height = int(ifdInt(order, value, typ))
+				}
+			})
+		}
+	})
+	if exif.DateTime == "" {
+		exif.DateTime = modified
+	}
+	return exif, nil
+}
+
+// findEXIF returns the TIFF structure inside the JPEG's EXIF APP1 segment.
+func findEXIF(jpeg []byte) ([]byte, error) {
+	if len(jpeg) < 4 || jpeg[0] != 0xff || jpeg[1] != 0xd8 {
+		return nil, errors.New("exif: not a JPEG")
+	}
+	for i := 2; i+4 <= len(jpeg); {
+		if jpeg[i] != 0xff {
+			return nil, errNoEXIF
+		}
+		marker := jpeg[i+1]
+		if marker == 0xd8 || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) || marker == 0xff {
+			i += 2
+			continue
+		}
+		// Metadata segments come before the image data.
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(jpeg[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(jpeg) {
+			break
+		}
+		segment := jpeg[i+4 : end]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+		i = end
+	}
+	return nil, errNoEXIF
+}
+
+// readIFD calls fn for every entry of the image file directory at offset,
+// with the entry's 4-byte value field.
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32, fn func(tag uint16, value []byte, count uint32, typ uint16)) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return
+	}
+	n := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < n; i++ {
+		entry := int(offset) + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		e := tiff[entry : entry+12]
+		fn(order.Uint16(e), e[8:12], order.Uint32(e[4:]), order.Uint16(e[2:]))
+	}
+}
+
+// ifdInt decodes a SHORT (3) or LONG (4) value.
+func ifdInt(order binary.ByteOrder, value []byte, typ uint16) uint32 {
+	if typ == 3 {
+		return uint32(order.Uint16(value))
+	}
+	return order.Uint32(value)
+}
+
+// ifdString decodes an ASCII value, stored inline when it fits in four
+// bytes and at an offset otherwise.
+func ifdString(tiff []byte, order binary.ByteOrder, value []byte, count uint32) string {
+	data := value
+	if count > 4 {
+		offset := uint64(order.Uint32(value))
+		if offset+uint64(count) > uint64(len(tiff)) {
+			return ""
+		}
+		data = tiff[offset : offset+uint64(count)]
+	} else {
+		data = data[:count]
+	}
+	return strings.TrimRight(string(data), "\x00 ")
+}
+
+// Orient transforms an image stored with the given EXIF orientation so
+// that it displays upright.
+func Orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return FlipHorizontal(img)
+	case 3:
+		return Rotate180(img)
+	case 4:
+		return FlipVertical(img)
+	case 5:
+		return Transpose(img)
+	case 6:
+		return Rotate90(img)
+	case 7:
+		return Transverse(img)
+	case 8:
+		return Rotate270(img)
+	}
+	return img
+}
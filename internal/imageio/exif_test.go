@@ -0,0 +1,156 @@
+package imageio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+// exifSegment builds an APP1 segment whose first IFD holds the
+// orientation and a pointer to an EXIF IFD with the pixel width and
+// capture date.
+func exifSegment(order binary.AppendByteOrder, orientation uint16) []byte {
+	const date = "2024:05:06 07:08:09\x00"
+	entry := func(b []byte, tag, typ uint16, count, value uint32) []byte {
+		b = order.AppendUint16(b, tag)
+		b = order.AppendUint16(b, typ)
+		b = order.AppendUint32(b, count)
+		if typ == 3 {
+			b = order.AppendUint16(b, uint16(value))
+			return append(b, 0, 0)
+		}
+		return order.AppendUint32(b, value)
+	}
+
+	tiff := []byte("II")
+	if order == binary.BigEndian {
+		tiff = []byte("MM")
+	}
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 8)
+	// IFD0 at 8: two entries, then the EXIF IFD at 8+2+24+4 = 38.
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = entry(tiff, tagOrientation, 3, 1, uint32(orientation))
+	tiff = entry(tiff, tagExifIFD, 4, 1, 38)
+	tiff = order.AppendUint32(tiff, 0)
+	// EXIF IFD at 38: two entries, then the date at 38+2+24+4 = 68.
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = entry(tiff, tagPixelXDimension, 4, 1, 640)
+	tiff = entry(tiff, tagDateTimeOriginal, 2, uint32(len(date)), 68)
+	tiff = order.AppendUint32(tiff, 0)
+	tiff = append(tiff, date...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withEXIF inserts an EXIF segment right after the JPEG's SOI marker.
+func withEXIF(jpegData []byte, order binary.AppendByteOrder, orientation uint16) []byte {
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, exifSegment(order, orientation)...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestReadEXIF(t *testing.T) {
+	soi := []byte{0xff, 0xd8}
+	for _, order := range []binary.AppendByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for orientation := uint16(1); orientation <= 9; orientation++ {
+			exif, err := ReadEXIF(withEXIF(append(soi, 0xff, 0xd9), order, orientation))
+			if err != nil {
+				t.Fatalf("%v orientation %d: %v", order, orientation, err)
+			}
+			want := int(orientation)
+			if orientation > 8 {
+				want = 1
+			}
+			if exif.Orientation != want {
+				t.Errorf("%v orientation %d: got %d, want %d", order, orientation, exif.Orientation, want)
+			}
+			if exif.Width != 640 || exif.DateTime != "2024:05:06 07:08:09" {
+				t.Errorf("%v: got width %d, date %q", order, exif.Width, exif.DateTime)
+			}
+		}
+	}
+
+	for _, data := range [][]byte{nil, []byte("not a jpeg"), append(soi, 0xff, 0xd9)} {
+		if _, err := ReadEXIF(data); err == nil {
+			t.Errorf("ReadEXIF(%q) succeeded", data)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// The stored image is
+	//
+	//	a b c
+	//	d e f
+	tests := []struct {
+		orientation int
+		want        string
+	}{
+		{1, "abc def"},
+		{2, "cba fed"},
+		{3, "fed cba"},
+		{4, "def abc"},
+		{5, "ad be cf"},
+		{6, "da eb fc"},
+		{7, "fc eb da"},
+		{8, "cf be ad"},
+	}
+	in := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i, ch := range "abcdef" {
+		in.Set(i%3, i/3, color.NRGBA{uint8(ch), 0, 0, 0xff})
+	}
+	for _, tt := range tests {
+		if got := letters(Orient(in, tt.orientation)); got != tt.want {
+			t.Errorf("Orient(%d) = %q, want %q", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+// letters returns the red channel of each pixel as a character, with
+// rows separated by spaces.
+func letters(img image.Image) string {
+	b := img.Bounds()
+	var rows []string
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var row []byte
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			row = append(row, byte(r>>8))
+		}
+		rows = append(rows, string(row))
+	}
+	return strings.Join(rows, " ")
+}
+
+func TestDecodeAutoOrient(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withEXIF(buf.Bytes(), binary.BigEndian, 6)
+
+	tests := []struct {
+		opts DecodeOptions
+		want image.Point
+	}{
+		{DecodeOptions{AutoOrient: true}, image.Pt(8, 16)},
+		{DecodeOptions{}, image.Pt(16, 8)},
+	}
+	for _, tt := range tests {
+		img, err := Decode(bytes.NewReader(data), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := img.Bounds().Size(); got != tt.want {
+			t.Errorf("Decode(%+v) size = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
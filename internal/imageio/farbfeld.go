@@ -0,0 +1,50 @@
+package imageio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+func init() {
+	image.RegisterFormat("farbfeld", "farbfeld", decodeFarbfeld, decodeFarbfeldConfig)
+}
+
+func readFarbfeldHeader(r io.Reader) (width, height int, err error) {
+	var header [16]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, fmt.Errorf("farbfeld: %w", errTruncated)
+	}
+	if string(header[:8]) != "farbfeld" {
+		return 0, 0, fmt.Errorf("farbfeld: not a farbfeld image")
+	}
+	width = int(binary.BigEndian.Uint32(header[8:]))
+	height = int(binary.BigEndian.Uint32(header[12:]))
+	return width, height, checkDimensions("farbfeld", width, height)
+}
+
+func decodeFarbfeldConfig(r io.Reader) (image.Config, error) {
+	width, height, err := readFarbfeldHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.NRGBA64Model, Width: width, Height: height}, nil
+}
+
+// decodeFarbfeld decodes a farbfeld image: 16-bit big-endian RGBA,
+// not premultiplied, which is exactly the layout of image.NRGBA64.
+func decodeFarbfeld(r io.Reader) (image.Image, error) {
+	width, height, err := readFarbfeldHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readImageData("farbfeld", r, 8*width*height)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA64(image.Rect(0, 0, width, height))
+	copy(img.Pix, data)
+	return img, nil
+}
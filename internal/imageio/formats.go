@@ -0,0 +1,39 @@
+package imageio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxPixels bounds the size of images the in-tree decoders allocate. They
+// also check that the input holds enough data for the image before
+// allocating it, so a corrupt header cannot exhaust memory.
+const maxPixels = 1 << 28
+
+var errTruncated = errors.New("unexpected end of image data")
+
+// checkDimensions validates the dimensions read from an image header.
+func checkDimensions(format string, width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%s: invalid dimensions %dx%d", format, width, height)
+	}
+	if width > maxPixels/height {
+		return fmt.Errorf("%s: image too large (%dx%d)", format, width, height)
+	}
+	return nil
+}
+
+// readImageData reads the rest of an image after its header, failing
+// unless it is at least minBytes long: the least the encoded pixels can
+// take up. Decoders call it before allocating the image.
+func readImageData(format string, r io.Reader, minBytes int) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < minBytes {
+		return nil, fmt.Errorf("%s: %w", format, errTruncated)
+	}
+	return data, nil
+}
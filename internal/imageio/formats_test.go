@@ -0,0 +1,275 @@
+package imageio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+var (
+	black = color.NRGBA{0, 0, 0, 0xff}
+	white = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	red   = color.NRGBA{0xff, 0, 0, 0xff}
+	green = color.NRGBA{0, 0xff, 0, 0xff}
+	blue  = color.NRGBA{0, 0, 0xff, 0xff}
+)
+
+// decodeTest inputs go through image.Decode, so each format must also be
+// recognised from its magic, and the pixels are compared row by row.
+type decodeTest struct {
+	name    string
+	data    []byte
+	format  string
+	want    [][]color.NRGBA
+	wantErr bool
+}
+
+func runDecodeTests(t *testing.T, tests []decodeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, format, err := image.Decode(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if format != tt.format {
+				t.Errorf("format = %q, want %q", format, tt.format)
+			}
+			if got := nrgbaRows(img); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pixels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func nrgbaRows(img image.Image) [][]color.NRGBA {
+	b := img.Bounds()
+	rows := make([][]color.NRGBA, b.Dy())
+	for y := range rows {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			rows[y] = append(rows[y], color.NRGBAModel.Convert(img.At(x, b.Min.Y+y)).(color.NRGBA))
+		}
+	}
+	return rows
+}
+
+// bmp assembles a bitmap with a 40-byte BITMAPINFOHEADER. extra holds
+// what goes between the header and the pixels: masks or a palette.
+func bmp(width, height, bpp, compression int, extra, pixels []byte) []byte {
+	offset := 14 + 40 + len(extra)
+	data := []byte("BM")
+	data = binary.LittleEndian.AppendUint32(data, uint32(offset+len(pixels)))
+	data = binary.LittleEndian.AppendUint32(data, 0)
+	data = binary.LittleEndian.AppendUint32(data, uint32(offset))
+	data = binary.LittleEndian.AppendUint32(data, 40)
+	data = binary.LittleEndian.AppendUint32(data, uint32(int32(width)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(int32(height)))
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	data = binary.LittleEndian.AppendUint16(data, uint16(bpp))
+	data = binary.LittleEndian.AppendUint32(data, uint32(compression))
+	data = append(data, make([]byte, 20)...)
+	data = append(data, extra...)
+	return append(data, pixels...)
+}
+
+func TestDecodeBMP(t *testing.T) {
+	palette := []byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0, 0, 0, 0xff, 0}
+	masks := binary.LittleEndian.AppendUint32(nil, 0x00ff0000)
+	masks = binary.LittleEndian.AppendUint32(masks, 0x0000ff00)
+	masks = binary.LittleEndian.AppendUint32(masks, 0x000000ff)
+	masks = binary.LittleEndian.AppendUint32(masks, 0xff000000)
+
+	runDecodeTests(t, []decodeTest{
+		{
+			// Rows are stored bottom-up and padded to four bytes.
+			name: "24-bit",
+			data: bmp(2, 2, 24, bmpRGB, nil, []byte{
+				0, 0, 0xff, 0, 0xff, 0, 0, 0,
+				0xff, 0, 0, 0xff, 0xff, 0xff, 0, 0,
+			}),
+			format: "bmp",
+			want:   [][]color.NRGBA{{blue, white}, {red, green}},
+		},
+		{
+			name:   "8-bit top-down",
+			data:   bmp(3, -1, 8, bmpRGB, palette, []byte{2, 1, 0, 0}),
+			format: "bmp",
+			want:   [][]color.NRGBA{{red, white, black}},
+		},
+		{
+			name:   "1-bit",
+			data:   bmp(3, 1, 1, bmpRGB, palette[:8], []byte{0xa0, 0, 0, 0}),
+			format: "bmp",
+			want:   [][]color.NRGBA{{white, black, white}},
+		},
+		{
+			name:   "16-bit 555",
+			data:   bmp(2, 1, 16, bmpRGB, nil, []byte{0x00, 0x7c, 0x1f, 0x00}),
+			format: "bmp",
+			want:   [][]color.NRGBA{{red, blue}},
+		},
+		{
+			name:   "32-bit alpha bitfields",
+			data:   bmp(2, 1, 32, bmpAlphaBitfields, masks, []byte{0, 0, 0xff, 0x80, 0xff, 0, 0, 0xff}),
+			format: "bmp",
+			want:   [][]color.NRGBA{{{0xff, 0, 0, 0x80}, blue}},
+		},
+		{
+			// An alpha channel that is zero everywhere is not used.
+			name:   "32-bit zero alpha",
+			data:   bmp(1, 1, 32, bmpRGB, nil, []byte{0, 0xff, 0, 0}),
+			format: "bmp",
+			want:   [][]color.NRGBA{{green}},
+		},
+		{
+			name:    "truncated pixels",
+			data:    bmp(4, 4, 24, bmpRGB, nil, make([]byte, 10)),
+			wantErr: true,
+		},
+		{
+			name:    "RLE",
+			data:    bmp(1, 1, 8, 1, palette, []byte{0, 0, 0, 0}),
+			wantErr: true,
+		},
+	})
+}
+
+func TestDecodeNetpbm(t *testing.T) {
+	runDecodeTests(t, []decodeTest{
+		{
+			name:   "P1",
+			data:   []byte("P1\n# comment\n3 2\n0 1 0\n101\n"),
+			format: "pbm",
+			want:   [][]color.NRGBA{{white, black, white}, {black, white, black}},
+		},
+		{
+			// Rows of packed bitmaps are padded to whole bytes.
+			name:   "P4",
+			data:   []byte("P4 3 2\n\x40\xa0"),
+			format: "pbm",
+			want:   [][]color.NRGBA{{white, black, white}, {black, white, black}},
+		},
+		{
+			name:   "P2",
+			data:   []byte("P2\n2 1\n15\n0 15\n"),
+			format: "pgm",
+			want:   [][]color.NRGBA{{black, white}},
+		},
+		{
+			name:   "P5 16-bit",
+			data:   []byte("P5 2 1 65535\n\xff\xff\x00\x00"),
+			format: "pgm",
+			want:   [][]color.NRGBA{{white, black}},
+		},
+		{
+			name:   "P3",
+			data:   []byte("P3\n2 1\n255\n255 0 0  0 0 255\n"),
+			format: "ppm",
+			want:   [][]color.NRGBA{{red, blue}},
+		},
+		{
+			name:   "P6",
+			data:   []byte("P6 2 1 255\n\x00\xff\x00\xff\xff\xff"),
+			format: "ppm",
+			want:   [][]color.NRGBA{{green, white}},
+		},
+		{
+			name:   "P7 RGB_ALPHA",
+			data:   []byte("P7\nWIDTH 2\nHEIGHT 1\nDEPTH 4\nMAXVAL 255\nTUPLTYPE RGB_ALPHA\nENDHDR\n\xff\x00\x00\x80\x00\x00\xff\xff"),
+			format: "pam",
+			want:   [][]color.NRGBA{{{0xff, 0, 0, 0x80}, blue}},
+		},
+		{
+			name:   "P7 GRAYSCALE_ALPHA",
+			data:   []byte("P7\nWIDTH 1\nHEIGHT 1\nDEPTH 2\nMAXVAL 255\nTUPLTYPE GRAYSCALE_ALPHA\nENDHDR\n\x80\x40"),
+			format: "pam",
+			want:   [][]color.NRGBA{{{0x80, 0x80, 0x80, 0x40}}},
+		},
+		{
+			name:    "short data",
+			data:    []byte("P6 1000 1000 255\n\x00\x00\x00"),
+			wantErr: true,
+		},
+		{
+			name:    "bad maxval",
+			data:    []byte("P2 1 1 0\n0\n"),
+			wantErr: true,
+		},
+		{
+			name:    "bad depth",
+			data:    []byte("P7\nWIDTH 1\nHEIGHT 1\nDEPTH 5\nMAXVAL 255\nENDHDR\n\x00\x00\x00\x00\x00"),
+			wantErr: true,
+		},
+	})
+}
+
+func TestDecodeQOI(t *testing.T) {
+	qoi := func(width, height, channels int, chunks ...byte) []byte {
+		data := []byte("qoif")
+		data = binary.BigEndian.AppendUint32(data, uint32(width))
+		data = binary.BigEndian.AppendUint32(data, uint32(height))
+		data = append(data, byte(channels), 0)
+		data = append(data, chunks...)
+		return append(data, 0, 0, 0, 0, 0, 0, 0, 1)
+	}
+
+	runDecodeTests(t, []decodeTest{
+		{
+			// RGB red, a run of one, a diff of (-1, 0, +1) and an index
+			// back to red (hash 50).
+			name:   "rgb, run, diff, index",
+			data:   qoi(2, 2, 3, qoiOpRGB, 0xff, 0, 0, qoiOpRun|0, 0x5b, qoiOpIndex|50),
+			format: "qoi",
+			want:   [][]color.NRGBA{{red, red}, {{0xfe, 0, 1, 0xff}, red}},
+		},
+		{
+			// Luma: dg = +8, dr - dg = -8, db - dg = +7.
+			name:   "rgba, luma",
+			data:   qoi(2, 1, 4, qoiOpRGBA, 0x10, 0x20, 0x30, 0x80, qoiOpLuma|40, 0x0f),
+			format: "qoi",
+			want:   [][]color.NRGBA{{{0x10, 0x20, 0x30, 0x80}, {0x10, 0x28, 0x3f, 0x80}}},
+		},
+		{
+			name:    "truncated",
+			data:    qoi(100, 100, 3, qoiOpRGB, 0xff, 0, 0)[:20],
+			wantErr: true,
+		},
+	})
+}
+
+func TestDecodeFarbfeld(t *testing.T) {
+	farbfeld := func(width, height int, pixels ...uint16) []byte {
+		data := []byte("farbfeld")
+		data = binary.BigEndian.AppendUint32(data, uint32(width))
+		data = binary.BigEndian.AppendUint32(data, uint32(height))
+		for _, v := range pixels {
+			data = binary.BigEndian.AppendUint16(data, v)
+		}
+		return data
+	}
+
+	runDecodeTests(t, []decodeTest{
+		{
+			name:   "two pixels",
+			data:   farbfeld(1, 2, 0xffff, 0, 0, 0xffff, 0, 0, 0xffff, 0x8080),
+			format: "farbfeld",
+			want:   [][]color.NRGBA{{red}, {{0, 0, 0xff, 0x80}}},
+		},
+		{
+			name:    "truncated",
+			data:    farbfeld(2, 2, 0, 0, 0, 0),
+			wantErr: true,
+		},
+		{
+			name:    "zero width",
+			data:    farbfeld(0, 2),
+			wantErr: true,
+		},
+	})
+}
@@ -5,22 +5,18 @@ import (
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"os"
 )
 
-// LoadImage loads an image from the specified file path.
-// Supports PNG, JPEG, and GIF (single frame) formats.
-func LoadImage(path string) (image.Image, error) {
-	file, err := os.Open(path)
+// LoadImage loads an image from the specified file path, or from
+// standard input if the path is "-".
+// Supports PNG, JPEG, GIF (single frame), and the in-tree BMP, Netpbm,
+// QOI and farbfeld decoders.
+func LoadImage(path string, opts DecodeOptions) (image.Image, error) {
+	file, err := open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return nil, err
-	}
-
-	return img, nil
+	return Decode(file, opts)
 }
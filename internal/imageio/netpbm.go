@@ -0,0 +1,308 @@
+package imageio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	image.RegisterFormat("pbm", "P1", decodeNetpbm, decodeNetpbmConfig)
+	image.RegisterFormat("pbm", "P4", decodeNetpbm, decodeNetpbmConfig)
+	image.RegisterFormat("pgm", "P2", decodeNetpbm, decodeNetpbmConfig)
+	image.RegisterFormat("pgm", "P5", decodeNetpbm, decodeNetpbmConfig)
+	image.RegisterFormat("ppm", "P3", decodeNetpbm, decodeNetpbmConfig)
+	image.RegisterFormat("ppm", "P6", decodeNetpbm, decodeNetpbmConfig)
+	image.RegisterFormat("pam", "P7", decodeNetpbm, decodeNetpbmConfig)
+}
+
+// netpbmHeader describes a PBM, PGM, PPM or PAM image.
+type netpbmHeader struct {
+	magic         string
+	width, height int
+	depth         int // channels per pixel
+	maxval        int
+	bitmap        bool // PBM: 1 is black
+}
+
+// minDataSize returns the least number of bytes the image data can take:
+// every ASCII sample needs at least a digit, binary samples one or two
+// bytes, and packed bitmaps a bit per pixel with rows padded to bytes.
+func (h *netpbmHeader) minDataSize() int {
+	samples := h.width * h.height * h.depth
+	switch h.magic {
+	case "P1", "P2", "P3":
+		return samples
+	case "P4":
+		return (h.width + 7) / 8 * h.height
+	}
+	if h.maxval > 255 {
+		return 2 * samples
+	}
+	return samples
+}
+
+func decodeNetpbmConfig(r io.Reader) (image.Config, error) {
+	h, err := readNetpbmHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	model := color.NRGBAModel
+	if h.depth == 1 {
+		model = color.GrayModel
+	}
+	return image.Config{ColorModel: model, Width: h.width, Height: h.height}, nil
+}
+
+// decodeNetpbm decodes the Netpbm family: PBM, PGM and PPM in their
+// ASCII (P1-P3) and binary (P4-P6) forms, and PAM (P7) with 1 to 4
+// channels. Samples are scaled from maxval to 8 bits.
+func decodeNetpbm(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readNetpbmHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readImageData("netpbm", br, h.minDataSize())
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := netpbmSampleReader(bufio.NewReader(bytes.NewReader(data)), h)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := func(v int) uint8 {
+		if h.bitmap {
+			if v != 0 {
+				return 0
+			}
+			return 0xff
+		}
+		return uint8(min(v, h.maxval) * 255 / h.maxval)
+	}
+
+	rect := image.Rect(0, 0, h.width, h.height)
+	if h.depth == 1 {
+		img := image.NewGray(rect)
+		for y := 0; y < h.height; y++ {
+			for x := 0; x < h.width; x++ {
+				v, err := next(x)
+				if err != nil {
+					return nil, err
+				}
+				img.Pix[y*img.Stride+x] = scale(v)
+			}
+		}
+		return img, nil
+	}
+
+	img := image.NewNRGBA(rect)
+	var s [4]int
+	for y := 0; y < h.height; y++ {
+		for x := 0; x < h.width; x++ {
+			for c := 0; c < h.depth; c++ {
+				if s[c], err = next(x); err != nil {
+					return nil, err
+				}
+			}
+			var px color.NRGBA
+			switch h.depth {
+			case 2: // grayscale with alpha
+				g := scale(s[0])
+				px = color.NRGBA{g, g, g, scale(s[1])}
+			case 3:
+				px = color.NRGBA{scale(s[0]), scale(s[1]), scale(s[2]), 0xff}
+			default:
+				px = color.NRGBA{scale(s[0]), scale(s[1]), scale(s[2]), scale(s[3])}
+			}
+			img.SetNRGBA(x, y, px)
+		}
+	}
+	return img, nil
+}
+
+// netpbmSampleReader returns a function reading the next sample; x is
+// the column of the pixel, which packed bitmaps need to skip row padding.
+func netpbmSampleReader(br *bufio.Reader, h *netpbmHeader) (func(x int) (int, error), error) {
+	switch h.magic {
+	case "P1":
+		// ASCII bitmaps may omit the whitespace between digits.
+		return func(int) (int, error) {
+			for {
+				b, err := br.ReadByte()
+				if err != nil {
+					return 0, fmt.Errorf("pbm: %w", errTruncated)
+				}
+				switch {
+				case b == '0' || b == '1':
+					return int(b - '0'), nil
+				case b == '#':
+					br.ReadString('\n')
+				}
+			}
+		}, nil
+	case "P2", "P3":
+		return func(int) (int, error) {
+			return readNetpbmInt(br)
+		}, nil
+	case "P4":
+		var bits byte
+		return func(x int) (int, error) {
+			if x%8 == 0 {
+				b, err := br.ReadByte()
+				if err != nil {
+					return 0, fmt.Errorf("pbm: %w", errTruncated)
+				}
+				bits = b
+			}
+			return int(bits>>(7-x%8)) & 1, nil
+		}, nil
+	default:
+		// Binary samples are one byte, or two big-endian bytes above 255.
+		wide := h.maxval > 255
+		return func(int) (int, error) {
+			hi, err := br.ReadByte()
+			if err != nil {
+				return 0, fmt.Errorf("netpbm: %w", errTruncated)
+			}
+			if !wide {
+				return int(hi), nil
+			}
+			lo, err := br.ReadByte()
+			if err != nil {
+				return 0, fmt.Errorf("netpbm: %w", errTruncated)
+			}
+			return int(hi)<<8 | int(lo), nil
+		}, nil
+	}
+}
+
+func readNetpbmHeader(br *bufio.Reader) (*netpbmHeader, error) {
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	h := &netpbmHeader{magic: string(magic), depth: 1, maxval: 1}
+
+	var err error
+	switch h.magic {
+	case "P1", "P4":
+		h.bitmap = true
+		h.width, h.height, err = readNetpbmDimensions(br)
+	case "P2", "P5", "P3", "P6":
+		if h.width, h.height, err = readNetpbmDimensions(br); err != nil {
+			break
+		}
+		h.maxval, err = readNetpbmInt(br)
+		if h.magic == "P3" || h.magic == "P6" {
+			h.depth = 3
+		}
+	case "P7":
+		err = readPAMHeader(br, h)
+	default:
+		return nil, fmt.Errorf("netpbm: unknown magic %q", h.magic)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h.maxval < 1 || h.maxval > 65535 {
+		return nil, fmt.Errorf("netpbm: invalid maxval %d", h.maxval)
+	}
+	if h.depth < 1 || h.depth > 4 {
+		return nil, fmt.Errorf("netpbm: unsupported depth %d", h.depth)
+	}
+	if err := checkDimensions("netpbm", h.width, h.height); err != nil {
+		return nil, err
+	}
+
+	// A single whitespace byte separates the header from binary data;
+	// readNetpbmInt has already consumed it.
+	return h, nil
+}
+
+func readNetpbmDimensions(br *bufio.Reader) (int, int, error) {
+	w, err := readNetpbmInt(br)
+	if err != nil {
+		return 0, 0, err
+	}
+	h, err := readNetpbmInt(br)
+	return w, h, err
+}
+
+// readNetpbmInt reads a decimal number, skipping whitespace and comments
+// before it and consuming the single whitespace byte after it.
+func readNetpbmInt(br *bufio.Reader) (int, error) {
+	var digits []byte
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if len(digits) > 0 && err == io.EOF {
+				break
+			}
+			return 0, fmt.Errorf("netpbm: %w", errTruncated)
+		}
+		if b >= '0' && b <= '9' {
+			digits = append(digits, b)
+			continue
+		}
+		if len(digits) > 0 {
+			break
+		}
+		switch b {
+		case '#':
+			br.ReadString('\n')
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+		default:
+			return 0, fmt.Errorf("netpbm: unexpected byte %q in header", b)
+		}
+	}
+	return strconv.Atoi(string(digits))
+}
+
+// readPAMHeader reads the KEY value lines of a PAM header up to ENDHDR.
+func readPAMHeader(br *bufio.Reader, h *netpbmHeader) error {
+	tupleType := ""
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("pam: %w", errTruncated)
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "ENDHDR" {
+			break
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		n, _ := strconv.Atoi(fields[1])
+		switch fields[0] {
+		case "WIDTH":
+			h.width = n
+		case "HEIGHT":
+			h.height = n
+		case "DEPTH":
+			h.depth = n
+		case "MAXVAL":
+			h.maxval = n
+		case "TUPLTYPE":
+			tupleType = fields[1]
+		}
+	}
+
+	// Unlike PBM, PAM black-and-white images store 1 for white.
+	if strings.HasPrefix(tupleType, "BLACKANDWHITE") {
+		h.maxval = 1
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package imageio
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions lists the file extensions of the image formats that can be
+// decoded.
+var Extensions = []string{
+	".png", ".jpg", ".jpeg", ".gif", ".bmp",
+	".pbm", ".pgm", ".ppm", ".pnm", ".pam", ".qoi", ".ff",
+}
+
+// IsSupported reports whether the path has the extension of a decodable
+// image format.
+func IsSupported(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandPaths replaces every directory in paths with the supported images
+// it contains, recursively and in lexical order. Other paths are kept as
+// given, so an explicitly named file is never skipped.
+func ExpandPaths(paths []string) ([]string, error) {
+	var expanded []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			expanded = append(expanded, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && IsSupported(p) {
+				expanded = append(expanded, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,127 @@
+package imageio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+func init() {
+	image.RegisterFormat("qoi", "qoif", decodeQOI, decodeQOIConfig)
+}
+
+// QOI chunk tags (https://qoiformat.org/qoi-specification.pdf).
+const (
+	qoiOpIndex = 0x00
+	qoiOpDiff  = 0x40
+	qoiOpLuma  = 0x80
+	qoiOpRun   = 0xc0
+	qoiOpRGB   = 0xfe
+	qoiOpRGBA  = 0xff
+	qoiMask2   = 0xc0
+
+	qoiMaxRun = 62
+)
+
+func readQOIHeader(r io.Reader) (width, height int, err error) {
+	var header [14]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, fmt.Errorf("qoi: %w", errTruncated)
+	}
+	if string(header[:4]) != "qoif" {
+		return 0, 0, fmt.Errorf("qoi: not a QOI image")
+	}
+	width = int(binary.BigEndian.Uint32(header[4:]))
+	height = int(binary.BigEndian.Uint32(header[8:]))
+	return width, height, checkDimensions("qoi", width, height)
+}
+
+func decodeQOIConfig(r io.Reader) (image.Config, error) {
+	width, height, err := readQOIHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.NRGBAModel, Width: width, Height: height}, nil
+}
+
+// decodeQOI decodes a "Quite OK Image". Both the RGB and RGBA channel
+// variants decode to NRGBA; the colour space byte is ignored.
+func decodeQOI(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	width, height, err := readQOIHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	// A chunk encodes at most qoiMaxRun pixels, and the stream ends with
+	// an 8 byte marker.
+	data, err := readImageData("qoi", br, (width*height+qoiMaxRun-1)/qoiMaxRun+8)
+	if err != nil {
+		return nil, err
+	}
+	dr := bytes.NewReader(data)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	var index [64]color.NRGBA
+	px := color.NRGBA{A: 0xff}
+	run := 0
+
+	readByte := func() (byte, error) {
+		b, err := dr.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("qoi: %w", errTruncated)
+		}
+		return b, nil
+	}
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		if run > 0 {
+			run--
+		} else {
+			b, err := readByte()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case b == qoiOpRGB || b == qoiOpRGBA:
+				n := 3
+				if b == qoiOpRGBA {
+					n = 4
+				}
+				var c [4]byte
+				for j := 0; j < n; j++ {
+					if c[j], err = readByte(); err != nil {
+						return nil, err
+					}
+				}
+				px.R, px.G, px.B = c[0], c[1], c[2]
+				if n == 4 {
+					px.A = c[3]
+				}
+			case b&qoiMask2 == qoiOpIndex:
+				px = index[b]
+			case b&qoiMask2 == qoiOpDiff:
+				px.R += (b>>4)&0x03 - 2
+				px.G += (b>>2)&0x03 - 2
+				px.B += b&0x03 - 2
+			case b&qoiMask2 == qoiOpLuma:
+				b2, err := readByte()
+				if err != nil {
+					return nil, err
+				}
+				dg := b&0x3f - 32
+				px.R += dg - 8 + (b2>>4)&0x0f
+				px.G += dg
+				px.B += dg - 8 + b2&0x0f
+			case b&qoiMask2 == qoiOpRun:
+				run = int(b & 0x3f)
+			}
+			index[(int(px.R)*3+int(px.G)*5+int(px.B)*7+int(px.A)*11)%64] = px
+		}
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = px.R, px.G, px.B, px.A
+	}
+	return img, nil
+}
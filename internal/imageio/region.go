@@ -0,0 +1,98 @@
+package imageio
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Length is a distance in pixels, or a percentage of the image size.
+type Length struct {
+	Value   float64
+	Percent bool
+}
+
+// Pixels resolves the length against an image dimension of size pixels.
+func (l Length) Pixels(size int) int {
+	if l.Percent {
+		return int(math.Round(l.Value * float64(size) / 100))
+	}
+	return int(math.Round(l.Value))
+}
+
+// ParseLength parses "120" (pixels) or "25%".
+func ParseLength(s string) (Length, error) {
+	s = strings.TrimSpace(s)
+	l := Length{}
+	if strings.HasSuffix(s, "%") {
+		l.Percent = true
+		s = strings.TrimSuffix(s, "%")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return Length{}, fmt.Errorf("invalid length %q", s)
+	}
+	l.Value = v
+	return l, nil
+}
+
+// parseLengths parses n comma-separated lengths.
+func parseLengths(s string, n int, form string) ([]Length, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("invalid value %q: expected %s", s, form)
+	}
+	lengths := make([]Length, n)
+	for i, p := range parts {
+		l, err := ParseLength(p)
+		if err != nil {
+			return nil, err
+		}
+		lengths[i] = l
+	}
+	return lengths, nil
+}
+
+// Region is a rectangle whose position and size may be percentages.
+type Region struct {
+	X, Y, Width, Height Length
+}
+
+// ParseRegion parses "x,y,w,h", each in pixels or percent, for example
+// "100,50,640,480" or "25%,25%,50%,50%".
+func ParseRegion(s string) (Region, error) {
+	l, err := parseLengths(s, 4, "x,y,w,h")
+	if err != nil {
+		return Region{}, err
+	}
+	return Region{X: l[0], Y: l[1], Width: l[2], Height: l[3]}, nil
+}
+
+// Rect resolves the region against image bounds.
+func (r Region) Rect(bounds image.Rectangle) image.Rectangle {
+	x := r.X.Pixels(bounds.Dx())
+	y := r.Y.Pixels(bounds.Dy())
+	return image.Rect(x, y, x+r.Width.Pixels(bounds.Dx()), y+r.Height.Pixels(bounds.Dy())).Add(bounds.Min)
+}
+
+// Point is a position whose coordinates may be percentages.
+type Point struct {
+	X, Y Length
+}
+
+// ParsePoint parses "x,y", each in pixels or percent.
+func ParsePoint(s string) (Point, error) {
+	l, err := parseLengths(s, 2, "x,y")
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{X: l[0], Y: l[1]}, nil
+}
+
+// Resolve returns the point in pixels from the top left of an image of
+// the given bounds.
+func (p Point) Resolve(bounds image.Rectangle) image.Point {
+	return image.Pt(p.X.Pixels(bounds.Dx()), p.Y.Pixels(bounds.Dy()))
+}
@@ -0,0 +1,64 @@
+package imageio
+
+import (
+	"image"
+	"testing"
+)
+
+func TestParseRegion(t *testing.T) {
+	bounds := image.Rect(10, 20, 210, 120)
+	tests := []struct {
+		in      string
+		want    image.Rectangle
+		wantErr bool
+	}{
+		{in: "0,0,200,100", want: image.Rect(10, 20, 210, 120)},
+		{in: "5, 10, 20, 30", want: image.Rect(15, 30, 35, 60)},
+		{in: "25%,25%,50%,50%", want: image.Rect(60, 45, 160, 95)},
+		{in: "10%,0,50,100%", want: image.Rect(30, 20, 80, 120)},
+		{in: "1.5,2.4,3,4", want: image.Rect(12, 22, 15, 26)},
+		{in: "0,0,10", wantErr: true},
+		{in: "0,0,10,10,10", wantErr: true},
+		{in: "a,0,10,10", wantErr: true},
+		{in: "-5,0,10,10", wantErr: true},
+		{in: "0,0,%,10", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		region, err := ParseRegion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRegion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := region.Rect(bounds); got != tt.want {
+			t.Errorf("ParseRegion(%q).Rect(%v) = %v, want %v", tt.in, bounds, got, tt.want)
+		}
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	bounds := image.Rect(0, 0, 200, 100)
+	tests := []struct {
+		in      string
+		want    image.Point
+		wantErr bool
+	}{
+		{in: "50%,50%", want: image.Pt(100, 50)},
+		{in: "30,70%", want: image.Pt(30, 70)},
+		{in: "50%", wantErr: true},
+		{in: "x,y", wantErr: true},
+	}
+	for _, tt := range tests {
+		p, err := ParsePoint(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePoint(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && p.Resolve(bounds) != tt.want {
+			t.Errorf("ParsePoint(%q).Resolve = %v, want %v", tt.in, p.Resolve(bounds), tt.want)
+		}
+	}
+}
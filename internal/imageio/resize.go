@@ -5,36 +5,43 @@ import (
 	"math"
 )
 
-// Terminal characters are taller than wide.
+// Terminal characters are taller than wide. CharAspectRatio is the
+// width/height ratio of a cell assumed when the terminal does not report it.
 const CharAspectRatio = 0.5
 
-// ResizeForTerminal resizes an image to fit within terminal bounds
-func ResizeForTerminal(img image.Image, termWidth, termHeight int) image.Image {
+// ResizeForTerminal resizes an image to fit within terminal bounds.
+// cellAspect is the width/height ratio of a character cell; zero means
+// CharAspectRatio.
+func ResizeForTerminal(img image.Image, termWidth, termHeight int, cellAspect float64) image.Image {
+	if cellAspect <= 0 {
+		cellAspect = CharAspectRatio
+	}
+
 	bounds := img.Bounds()
 	imgWidth := bounds.Dx()
 	imgHeight := bounds.Dy()
 
 	imgAspect := float64(imgWidth) / float64(imgHeight)
 
-	effectiveTermHeight := float64(termHeight) / CharAspectRatio
+	effectiveTermHeight := float64(termHeight) / cellAspect
 	termAspect := float64(termWidth) / effectiveTermHeight
 
 	var newWidth, newHeight int
 	if imgAspect > termAspect {
 		newWidth = termWidth
-		newHeight = int(float64(termWidth) / imgAspect * CharAspectRatio)
+		newHeight = int(float64(termWidth) / imgAspect * cellAspect)
 	} else {
 		newHeight = termHeight
-		newWidth = int(float64(termHeight) * imgAspect / CharAspectRatio)
+		newWidth = int(float64(termHeight) * imgAspect / cellAspect)
 	}
 
 	if newWidth > termWidth {
 		newWidth = termWidth
-		newHeight = int(float64(termWidth) / imgAspect * CharAspectRatio)
+		newHeight = int(float64(termWidth) / imgAspect * cellAspect)
 	}
 	if newHeight > termHeight {
 		newHeight = termHeight
-		newWidth = int(float64(termHeight) * imgAspect / CharAspectRatio)
+		newWidth = int(float64(termHeight) * imgAspect / cellAspect)
 	}
 
 	resized := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
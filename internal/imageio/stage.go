@@ -0,0 +1,82 @@
+package imageio
+
+import (
+	"fmt"
+	"image"
+)
+
+// Stage is a geometric transform applied to the decoded image before it
+// is resized, such as a crop or rotation.
+type Stage interface {
+	// Name identifies the stage in debug output.
+	Name() string
+	Apply(img image.Image) image.Image
+}
+
+// CropStage keeps only a region of the image.
+type CropStage struct {
+	Region Region
+}
+
+func (s CropStage) Name() string { return "crop" }
+
+func (s CropStage) Apply(img image.Image) image.Image {
+	r := s.Region.Rect(img.Bounds()).Intersect(img.Bounds())
+	if r.Empty() {
+		return img
+	}
+	return Crop(img, r)
+}
+
+// RotateStage rotates the image clockwise by Degrees.
+type RotateStage struct {
+	Degrees float64
+}
+
+func (s RotateStage) Name() string { return fmt.Sprintf("rotate_%g", s.Degrees) }
+
+func (s RotateStage) Apply(img image.Image) image.Image {
+	return Rotate(img, s.Degrees)
+}
+
+// FlipStage mirrors the image horizontally, vertically or both.
+type FlipStage struct {
+	Horizontal, Vertical bool
+}
+
+// ParseFlip parses "h", "v" or "hv".
+func ParseFlip(s string) (FlipStage, error) {
+	switch s {
+	case "h":
+		return FlipStage{Horizontal: true}, nil
+	case "v":
+		return FlipStage{Vertical: true}, nil
+	case "hv", "vh":
+		return FlipStage{Horizontal: true, Vertical: true}, nil
+	}
+	return FlipStage{}, fmt.Errorf("invalid flip %q (use h, v or hv)", s)
+}
+
+func (s FlipStage) Name() string { return "flip" }
+
+func (s FlipStage) Apply(img image.Image) image.Image {
+	if s.Horizontal {
+		img = FlipHorizontal(img)
+	}
+	if s.Vertical {
+		img = FlipVertical(img)
+	}
+	return img
+}
+
+// ZoomStage magnifies the image by Factor around Focus.
+type ZoomStage struct {
+	Factor float64
+	Focus  Point
+}
+
+func (s ZoomStage) Name() string { return fmt.Sprintf("zoom_%g", s.Factor) }
+
+func (s ZoomStage) Apply(img image.Image) image.Image {
+	return Zoom(img, s.Factor, s.Focus.Resolve(img.Bounds()))
+}
@@ -0,0 +1,168 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// nrgbaImage returns the image as an NRGBA with bounds starting at 0,0,
+// converting it if necessary.
+func nrgbaImage(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Bounds().Min == (image.Point{}) {
+		return n
+	}
+	bounds := img.Bounds()
+	result := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+	return result
+}
+
+// remap builds a width x height image whose pixel at x, y is the source
+// pixel at src(x, y).
+func remap(img image.Image, width, height int, src func(x, y int) (int, int)) image.Image {
+	in := nrgbaImage(img)
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := src(x, y)
+			copy(out.Pix[out.PixOffset(x, y):][:4], in.Pix[in.PixOffset(sx, sy):][:4])
+		}
+	}
+	return out
+}
+
+// Rotate90 rotates the image 90 degrees clockwise.
+func Rotate90(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, h, w, func(x, y int) (int, int) { return y, h - 1 - x })
+}
+
+// Rotate180 rotates the image by 180 degrees.
+func Rotate180(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, w, h, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y })
+}
+
+// Rotate270 rotates the image 90 degrees counter-clockwise.
+func Rotate270(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, h, w, func(x, y int) (int, int) { return w - 1 - y, x })
+}
+
+// FlipHorizontal mirrors the image left to right.
+func FlipHorizontal(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, w, h, func(x, y int) (int, int) { return w - 1 - x, y })
+}
+
+// FlipVertical mirrors the image top to bottom.
+func FlipVertical(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, w, h, func(x, y int) (int, int) { return x, h - 1 - y })
+}
+
+// Transpose mirrors the image along its main diagonal.
+func Transpose(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, h, w, func(x, y int) (int, int) { return y, x })
+}
+
+// Transverse mirrors the image along its anti-diagonal.
+func Transverse(img image.Image) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return remap(img, h, w, func(x, y int) (int, int) { return w - 1 - y, h - 1 - x })
+}
+
+// Rotate rotates the image clockwise by an arbitrary angle in degrees,
+// sampling bilinearly. The result is the rotated image's bounding box;
+// the corners outside the source are transparent. Multiples of 90
+// degrees are rotated exactly.
+func Rotate(img image.Image, degrees float64) image.Image {
+	switch math.Mod(math.Mod(degrees, 360)+360, 360) {
+	case 0:
+		return img
+	case 90:
+		return Rotate90(img)
+	case 180:
+		return Rotate180(img)
+	case 270:
+		return Rotate270(img)
+	}
+
+	in := rgbaImage(img)
+	w, h := float64(in.Bounds().Dx()), float64(in.Bounds().Dy())
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	outW := int(math.Ceil(math.Abs(w*cos) + math.Abs(h*sin)))
+	outH := int(math.Ceil(math.Abs(w*sin) + math.Abs(h*cos)))
+	out := image.NewRGBA(image.Rect(0, 0, outW, outH))
+
+	for y := 0; y < outH; y++ {
+		for x := 0; x < outW; x++ {
+			// Rotate the pixel centre back into the source around the centres.
+			dx := float64(x) + 0.5 - float64(outW)/2
+			dy := float64(y) + 0.5 - float64(outH)/2
+			sx := dx*cos + dy*sin + w/2
+			sy := -dx*sin + dy*cos + h/2
+			out.SetRGBA(x, y, bilinear(in, sx-0.5, sy-0.5))
+		}
+	}
+	return out
+}
+
+// bilinear samples a premultiplied image between pixel centres; pixels
+// outside the image count as transparent.
+func bilinear(img *image.RGBA, x, y float64) color.RGBA {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	var sum [4]float64
+	for _, s := range [4]struct {
+		x, y int
+		w    float64
+	}{
+		{x0, y0, (1 - fx) * (1 - fy)},
+		{x0 + 1, y0, fx * (1 - fy)},
+		{x0, y0 + 1, (1 - fx) * fy},
+		{x0 + 1, y0 + 1, fx * fy},
+	} {
+		if !(image.Point{s.x, s.y}.In(img.Rect)) {
+			continue
+		}
+		c := img.RGBAAt(s.x, s.y)
+		sum[0] += float64(c.R) * s.w
+		sum[1] += float64(c.G) * s.w
+		sum[2] += float64(c.B) * s.w
+		sum[3] += float64(c.A) * s.w
+	}
+	return color.RGBA{uint8(sum[0] + 0.5), uint8(sum[1] + 0.5), uint8(sum[2] + 0.5), uint8(sum[3] + 0.5)}
+}
+
+// rgbaImage returns the image as a premultiplied RGBA with bounds starting
+// at 0,0, converting it if necessary.
+func rgbaImage(img image.Image) *image.RGBA {
+	if r, ok := img.(*image.RGBA); ok && r.Bounds().Min == (image.Point{}) {
+		return r
+	}
+	bounds := img.Bounds()
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+	return result
+}
+
+// Zoom magnifies the image by factor around a focal point, given in
+// pixels from its top left corner, by cropping to the area that the
+// magnified image would show. The area is kept inside the image, so a
+// focal point near an edge shifts it inwards.
+func Zoom(img image.Image, factor float64, focus image.Point) image.Image {
+	if factor <= 1 {
+		return img
+	}
+	bounds := img.Bounds()
+	w := max(1, int(math.Round(float64(bounds.Dx())/factor)))
+	h := max(1, int(math.Round(float64(bounds.Dy())/factor)))
+	x := min(max(focus.X-w/2, 0), bounds.Dx()-w)
+	y := min(max(focus.Y-h/2, 0), bounds.Dy()-h)
+	return Crop(img, image.Rect(x, y, x+w, y+h).Add(bounds.Min))
+}
@@ -0,0 +1,73 @@
+package imageio
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestZoom(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+	tests := []struct {
+		factor float64
+		focus  image.Point
+		want   image.Rectangle
+	}{
+		{1, image.Pt(50, 25), image.Rect(0, 0, 100, 50)},
+		{0.5, image.Pt(50, 25), image.Rect(0, 0, 100, 50)},
+		{2, image.Pt(50, 25), image.Rect(25, 13, 75, 38)},
+		{4, image.Pt(10, 10), image.Rect(0, 4, 25, 17)},
+		// A focus near the edge shifts the area inwards.
+		{2, image.Pt(100, 50), image.Rect(50, 25, 100, 50)},
+		{1000, image.Pt(0, 0), image.Rect(0, 0, 1, 1)},
+	}
+	for _, tt := range tests {
+		if got := Zoom(img, tt.factor, tt.focus).Bounds(); got != tt.want {
+			t.Errorf("Zoom(%g, %v) shows %v, want %v", tt.factor, tt.focus, got, tt.want)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// a b c
+	// d e f
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i, ch := range "abcdef" {
+		img.Set(i%3, i/3, color.NRGBA{uint8(ch), 0, 0, 0xff})
+	}
+
+	exact := []struct {
+		degrees float64
+		want    string
+	}{
+		{0, "abc def"},
+		{360, "abc def"},
+		{90, "da eb fc"},
+		{-270, "da eb fc"},
+		{180, "fed cba"},
+		{270, "cf be ad"},
+		{-90, "cf be ad"},
+	}
+	for _, tt := range exact {
+		if got := letters(Rotate(img, tt.degrees)); got != tt.want {
+			t.Errorf("Rotate(%g) = %q, want %q", tt.degrees, got, tt.want)
+		}
+	}
+
+	// Other angles grow to the rotated bounding box and leave its corners
+	// transparent.
+	square := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for i := range square.Pix {
+		square.Pix[i] = 0xff
+	}
+	rotated := Rotate(square, 45)
+	if got := rotated.Bounds().Size(); got != image.Pt(15, 15) {
+		t.Fatalf("Rotate(45) is %v, want 15x15", got)
+	}
+	if _, _, _, a := rotated.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("corner alpha = %d, want 0", a)
+	}
+	if _, _, _, a := rotated.At(7, 7).RGBA(); a != 0xffff {
+		t.Errorf("centre alpha = %d, want opaque", a)
+	}
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+// WriteANSI writes the frame as raw ANSI art (.ans): the same escape
+// sequences the terminal renderer emits, without clearing the screen.
+// The text is UTF-8, except with a SAUCE record: SAUCE-aware viewers read
+// the file as code page 437, so the glyphs are encoded as CP437 then.
+func WriteANSI(w io.Writer, f *frame.Frame, opts Options) error {
+	var buf bytes.Buffer
+	if err := terminal.WriteFrame(&buf, f, opts.Background, opts.Color, opts.Depth); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if opts.Sauce != nil {
+		data = encodeCP437(buf.String())
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	if opts.Sauce != nil {
+		return writeSauce(w, opts.Sauce, int64(len(data)), f.Width, f.Height)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+// maxANSIRows and maxANSIColumns bound the size of parsed ANSI art. The
+// cursor is kept inside them and a SAUCE width is capped to them, so that
+// a stray "ESC [ 999999999 B" or a bogus record cannot force a huge
+// allocation.
+const (
+	maxANSIRows    = 10000
+	maxANSIColumns = 1000
+)
+
+// ansiColor is an SGR colour: an index into the ANSI palette or an RGB
+// value. The zero value is the terminal's default colour.
+type ansiColor struct {
+	set   bool
+	index int // -1 for RGB
+	rgb   color.RGBA
+}
+
+func (c ansiColor) resolve(bright bool) color.Color {
+	if !c.set {
+		return nil
+	}
+	if c.index < 0 {
+		return c.rgb
+	}
+	idx := c.index
+	if bright && idx < 8 {
+		idx += 8
+	}
+	return terminal.ANSIPalette().Colors[idx]
+}
+
+type ansiCell struct {
+	ch     rune
+	fg, bg color.Color
+}
+
+// ansiParser interprets a stream of text and escape sequences onto a grid
+// of cells, the way a terminal of the given width would. Rows grow only as
+// far as the highest column written.
+type ansiParser struct {
+	width       int
+	rows        [][]ansiCell
+	x, y        int
+	savedX      int
+	savedY      int
+	wrapPending bool
+
+	fg, bg        ansiColor
+	bold, reverse bool
+	hasFg, hasBg  bool
+}
+
+// ParseANSI reads ANSI art into a frame. It understands SGR colours
+// (16, 256 and truecolor, foreground and background), resets, bold as
+// bright, reverse video and the cursor movements common in art files.
+// Text is read as UTF-8, or as code page 437 when it is not valid UTF-8.
+// A SAUCE record, if present, is stripped and supplies the width when
+// width is 0. Without either, lines do not wrap and the frame is as wide as
+// the longest line.
+func ParseANSI(r io.Reader, width int) (*frame.Frame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if sauceWidth, ok := sauceWidth(data); ok && width <= 0 {
+		width = sauceWidth
+	}
+	if i := bytes.IndexByte(data, 0x1a); i >= 0 {
+		data = data[:i]
+	}
+	var text []rune
+	if utf8.Valid(data) {
+		text = []rune(string(data))
+	} else {
+		text = make([]rune, len(data))
+		for i, b := range data {
+			if b < 0x80 {
+				text[i] = rune(b)
+			} else {
+				text[i] = cp437[b-0x80]
+			}
+		}
+	}
+
+	p := &ansiParser{width: maxANSIColumns}
+	if width > 0 {
+		p.width = min(width, maxANSIColumns)
+	}
+	p.run(text)
+	return p.frame(width > 0), nil
+}
+
+// ReadANSIFile reads a frame from an ANSI art file.
+func ReadANSIFile(path string) (*frame.Frame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseANSI(file, 0)
+}
+
+// sauceWidth returns the character width recorded in a trailing SAUCE
+// record of a character-type file.
+func sauceWidth(data []byte) (int, bool) {
+	if len(data) < 128 {
+		return 0, false
+	}
+	record := data[len(data)-128:]
+	if !bytes.HasPrefix(record, []byte("SAUCE00")) || record[94] != sauceDataTypeCharacter {
+		return 0, false
+	}
+	width := int(binary.LittleEndian.Uint16(record[96:]))
+	return width, width > 0
+}
+
+func (p *ansiParser) run(text []rune) {
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch ch {
+		case 0x1b:
+			i = p.escape(text, i)
+		case '\r':
+			p.x = 0
+			p.wrapPending = false
+		case '\n':
+			p.x = 0
+			p.y = min(p.y+1, maxANSIRows-1)
+			p.wrapPending = false
+		case '\t':
+			p.x = min((p.x/8+1)*8, p.width-1)
+		default:
+			if ch < 0x20 || ch == 0x7f {
+				continue
+			}
+			p.put(ch)
+		}
+	}
+}
+
+// escape handles the escape sequence starting at text[i] and returns the
+// index of its last rune.
+func (p *ansiParser) escape(text []rune, i int) int {
+	if i+1 >= len(text) {
+		return i
+	}
+	switch text[i+1] {
+	case '[':
+		// CSI: parameters and intermediates, then a final byte in @-~.
+		j := i + 2
+		for j < len(text) && (text[j] < 0x40 || text[j] > 0x7e) {
+			j++
+		}
+		if j >= len(text) {
+			return len(text) - 1
+		}
+		p.csi(string(text[i+2:j]), text[j])
+		return j
+	case ']', 'P', '_', '^':
+		// OSC and other strings run until BEL or ST (ESC \).
+		for j := i + 2; j < len(text); j++ {
+			if text[j] == 0x07 {
+				return j
+			}
+			if text[j] == 0x1b && j+1 < len(text) && text[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(text) - 1
+	}
+	return i + 1
+}
+
+func (p *ansiParser) csi(params string, final rune) {
+	if strings.HasPrefix(params, "?") || strings.HasPrefix(params, ">") {
+		// Private modes (cursor visibility, wrap mode...) do not affect cells.
+		return
+	}
+	args := parseParams(params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	switch final {
+	case 'm':
+		p.sgr(args)
+	case 'A':
+		p.y = max(p.y-arg(0, 1), 0)
+	case 'B':
+		p.y = min(p.y+arg(0, 1), maxANSIRows-1)
+	case 'C':
+		p.x = min(p.x+arg(0, 1), p.width-1)
+	case 'D':
+		p.x = max(p.x-arg(0, 1), 0)
+	case 'H', 'f':
+		p.y = min(arg(0, 1)-1, maxANSIRows-1)
+		p.x = min(arg(1, 1)-1, p.width-1)
+	case 'G':
+		p.x = min(arg(0, 1)-1, p.width-1)
+	case 'J':
+		if len(args) > 0 && args[0] == 2 {
+			p.rows = nil
+			p.x, p.y = 0, 0
+		}
+	case 'K':
+		if p.y < len(p.rows) {
+			row := p.rows[p.y]
+			for x := p.x; x < len(row); x++ {
+				row[x] = ansiCell{}
+			}
+		}
+	case 's':
+		p.savedX, p.savedY = p.x, p.y
+	case 'u':
+		p.x, p.y = p.savedX, p.savedY
+	}
+	p.wrapPending = false
+}
+
+func (p *ansiParser) sgr(args []int) {
+	if len(args) == 0 {
+		args = []int{0}
+	}
+	for i := 0; i < len(args); i++ {
+		n := args[i]
+		switch {
+		case n == 0:
+			p.fg, p.bg = ansiColor{}, ansiColor{}
+			p.bold, p.reverse = false, false
+		case n == 1:
+			p.bold = true
+		case n == 22:
+			p.bold = false
+		case n == 7:
+			p.reverse = true
+		case n == 27:
+			p.reverse = false
+		case n >= 30 && n <= 37:
+			p.fg = ansiColor{set: true, index: n - 30}
+		case n == 39:
+			p.fg = ansiColor{}
+		case n >= 40 && n <= 47:
+			p.bg = ansiColor{set: true, index: n - 40}
+		case n == 49:
+			p.bg = ansiColor{}
+		case n >= 90 && n <= 97:
+			p.fg = ansiColor{set: true, index: n - 90 + 8}
+		case n >= 100 && n <= 107:
+			p.bg = ansiColor{set: true, index: n - 100 + 8}
+		case n == 38 || n == 48:
+			c, consumed := extendedColor(args[i+1:])
+			i += consumed
+			if n == 38 {
+				p.fg = c
+			} else {
+				p.bg = c
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments after 38 or 48: "5;n" or "2;r;g;b".
+func extendedColor(args []int) (ansiColor, int) {
+	if len(args) >= 2 && args[0] == 5 {
+		return ansiColor{set: true, index: args[1] & 0xff}, 2
+	}
+	if len(args) >= 4 && args[0] == 2 {
+		return ansiColor{set: true, index: -1, rgb: color.RGBA{uint8(args[1]), uint8(args[2]), uint8(args[3]), 0xff}}, 4
+	}
+	return ansiColor{}, len(args)
+}
+
+func parseParams(s string) []int {
+	if s == "" {
+		return nil
+	}
+	// Colon sub-parameters (38:2:r:g:b) are treated like semicolons.
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ':' })
+	args := make([]int, len(fields))
+	for i, f := range fields {
+		args[i], _ = strconv.Atoi(f)
+	}
+	return args
+}
+
+func (p *ansiParser) put(ch rune) {
+	if p.wrapPending {
+		p.x = 0
+		p.y = min(p.y+1, maxANSIRows-1)
+		p.wrapPending = false
+	}
+
+	for len(p.rows) <= p.y {
+		p.rows = append(p.rows, nil)
+	}
+	if row := p.rows[p.y]; len(row) <= p.x {
+		p.rows[p.y] = append(row, make([]ansiCell, p.x+1-len(row))...)
+	}
+
+	fg := p.fg.resolve(p.bold)
+	bg := p.bg.resolve(false)
+	if p.reverse {
+		fg, bg = bg, fg
+		if fg == nil {
+			fg = terminal.ANSIPalette().Colors[0]
+		}
+		if bg == nil {
+			bg = terminal.ANSIPalette().Colors[7]
+		}
+	}
+	p.hasFg = p.hasFg || fg != nil
+	p.hasBg = p.hasBg || bg != nil
+	p.rows[p.y][p.x] = ansiCell{ch: ch, fg: fg, bg: bg}
+
+	// Like a terminal, wrap only when the next character arrives, so a
+	// full-width line followed by CR LF does not leave a blank line.
+	if p.x == p.width-1 {
+		p.wrapPending = true
+	} else {
+		p.x++
+	}
+}
+
+// frame converts the grid to a frame, p.width wide when fixed is set and
+// as wide as the longest row otherwise.
+func (p *ansiParser) frame(fixed bool) *frame.Frame {
+	width := p.width
+	if !fixed {
+		width = 0
+		for _, row := range p.rows {
+			width = max(width, len(row))
+		}
+	}
+	f := frame.New(width, len(p.rows))
+	if p.hasFg {
+		f.EnableColors()
+	}
+	if p.hasBg {
+		f.EnableBackgrounds()
+	}
+	for y, row := range p.rows {
+		for x := 0; x < width; x++ {
+			var cell ansiCell
+			if x < len(row) {
+				cell = row[x]
+			}
+			ch := cell.ch
+			if ch == 0 {
+				ch = ' '
+			}
+			f.Set(x, y, ch)
+			f.SetColor(x, y, cell.fg)
+			f.SetBackground(x, y, cell.bg)
+		}
+	}
+	return f
+}
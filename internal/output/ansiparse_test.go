@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+func TestParseANSIRoundTrip(t *testing.T) {
+	f := frame.New(4, 2)
+	f.EnableColors()
+	colors := []color.RGBA{{0xff, 0, 0, 0xff}, {0, 0x80, 0xff, 0xff}, {0x10, 0x20, 0x30, 0xff}}
+	for y := 0; y < f.Height; y++ {
+		for x, ch := range []rune("@█░.") {
+			f.Set(x, y, ch)
+			f.SetColor(x, y, colors[(x+y)%len(colors)])
+		}
+	}
+
+	tests := []struct {
+		name  string
+		sauce *Sauce
+	}{
+		{"utf-8", nil},
+		{"cp437 with sauce", &Sauce{Title: "round trip"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := Options{Background: terminal.BgNone, Color: true, Depth: terminal.DepthTrueColor, Sauce: tt.sauce}
+			if err := WriteANSI(&buf, f, opts); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseANSI(&buf, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Width != f.Width || got.Height != f.Height {
+				t.Fatalf("size = %dx%d, want %dx%d", got.Width, got.Height, f.Width, f.Height)
+			}
+			for y := 0; y < f.Height; y++ {
+				for x := 0; x < f.Width; x++ {
+					if got.Get(x, y) != f.Get(x, y) {
+						t.Errorf("cell (%d,%d) = %q, want %q", x, y, got.Get(x, y), f.Get(x, y))
+					}
+					if got.GetColor(x, y) != f.GetColor(x, y) {
+						t.Errorf("colour (%d,%d) = %v, want %v", x, y, got.GetColor(x, y), f.GetColor(x, y))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseANSIWidth(t *testing.T) {
+	sauce := func(width int) string {
+		rec := make([]byte, 128)
+		copy(rec, "SAUCE00")
+		rec[94] = sauceDataTypeCharacter
+		binary.LittleEndian.PutUint16(rec[96:], uint16(width))
+		return "\x1a" + string(rec)
+	}
+
+	tests := []struct {
+		name          string
+		in            string
+		width         int
+		wantW, wantH  int
+		wantFirstLine string
+	}{
+		{"longest line", "ab\nabcdef\nabc\n", 0, 6, 3, "ab    "},
+		{"no wrap without sauce", strings.Repeat("x", 120), 0, 120, 1, strings.Repeat("x", 120)},
+		{"sauce width wraps", "abcdef" + sauce(4), 0, 4, 2, "abcd"},
+		{"sauce width pads", "ab" + sauce(5), 0, 5, 1, "ab   "},
+		{"explicit width wins", "abcdef" + sauce(4), 3, 3, 2, "abc"},
+		{"sauce width capped", "x\x1b[9999Cy" + sauce(65535), 0, maxANSIColumns, 1, "x" + strings.Repeat(" ", maxANSIColumns-2) + "y"},
+		{"rows capped", "\x1b[9999Bx" + sauce(65535), 0, maxANSIColumns, maxANSIRows, strings.Repeat(" ", maxANSIColumns)},
+		{"cursor position", "\x1b[2;3Hx", 0, 3, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseANSI(strings.NewReader(tt.in), tt.width)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Width != tt.wantW || f.Height != tt.wantH {
+				t.Fatalf("size = %dx%d, want %dx%d", f.Width, f.Height, tt.wantW, tt.wantH)
+			}
+			if line := string(f.Cells[0]); line != tt.wantFirstLine && tt.wantFirstLine != "" {
+				t.Errorf("first line = %q, want %q", line, tt.wantFirstLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+// castHeader is the first line of an asciicast v2 recording
+// (https://docs.asciinema.org/manual/asciicast/v2/).
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// WriteCast writes the frames as an asciicast v2 recording that replays
+// them with their original delays. Each event only redraws the cells
+// that changed since the previous frame; the screen is only cleared when
+// the frame size changes.
+func WriteCast(w io.Writer, frames []*frame.Frame, delays []time.Duration, opts Options) error {
+	header := castHeader{
+		Version:   2,
+		Timestamp: time.Now().Unix(),
+		Env:       map[string]string{"TERM": castTerm(opts.Depth)},
+	}
+	for _, f := range frames {
+		header.Width = max(header.Width, f.Width)
+		header.Height = max(header.Height, f.Height)
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	renderer := terminal.NewRenderer(&buf, opts.Background, opts.Color, opts.Depth)
+	var elapsed time.Duration
+	for i, f := range frames {
+		buf.Reset()
+		if err := renderer.Update(f); err != nil {
+			return err
+		}
+
+		if err := writeCastEvent(enc, elapsed, buf.String()); err != nil {
+			return err
+		}
+		elapsed += frameDelay(delays, i)
+	}
+
+	// A final empty event keeps the last frame on screen for its delay.
+	if err := writeCastEvent(enc, elapsed, ""); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func writeCastEvent(enc *json.Encoder, at time.Duration, data string) error {
+	return enc.Encode([]interface{}{at.Seconds(), "o", data})
+}
+
+func castTerm(depth terminal.ColorDepth) string {
+	if depth >= terminal.Depth256 {
+		return "xterm-256color"
+	}
+	if term := os.Getenv("TERM"); term != "" {
+		return term
+	}
+	return "xterm"
+}
+
+func frameDelay(delays []time.Duration, i int) time.Duration {
+	if i < len(delays) && delays[i] > 0 {
+		return delays[i]
+	}
+	return frame.DefaultDelay
+}
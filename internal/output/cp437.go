@@ -0,0 +1,30 @@
+package output
+
+// cp437 maps the upper half of code page 437, the encoding of classic
+// DOS ANSI art, to Unicode.
+var cp437 = []rune("ÇüéâäàåçêëèïîìÄÅÉæÆôöòûùÿÖÜ¢£¥₧ƒáíóúñÑªº¿⌐¬½¼¡«»" +
+	"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐└┴┬├─┼╞╟╚╔╩╦╠═╬╧╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"αßΓπΣσµτΦΘΩδ∞φε∩≡±≥≤⌠⌡÷≈°∙·√ⁿ²■ ")
+
+// encodeCP437 converts s to code page 437, one byte per character. ASCII,
+// including control characters and escape sequences, is kept as it is;
+// characters code page 437 cannot represent become '?'.
+func encodeCP437(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		out = append(out, cp437Byte(r))
+	}
+	return out
+}
+
+func cp437Byte(r rune) byte {
+	if r < 0x80 {
+		return byte(r)
+	}
+	for i, c := range cp437 {
+		if c == r {
+			return byte(0x80 + i)
+		}
+	}
+	return '?'
+}
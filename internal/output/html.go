@@ -0,0 +1,209 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+const (
+	defaultFontFamily = "Menlo, Consolas, \"DejaVu Sans Mono\", monospace"
+	defaultFontSize   = 12
+)
+
+// run is a horizontal stretch of cells sharing the same colours.
+type run struct {
+	x    int
+	text string
+	fg   color.Color
+	bg   color.Color
+}
+
+// WriteHTML writes the frame as an HTML document, or with opts.Fragment as
+// a bare <pre> block for embedding. Neighbouring cells of the same colour
+// are merged into a single span.
+func WriteHTML(w io.Writer, f *frame.Frame, opts Options) error {
+	rows := colorRuns(f, opts.Color)
+
+	// In class mode every distinct colour gets a CSS class named after it,
+	// so fragments embedded in the same page agree on their rules.
+	seen := make(map[string]bool)
+	var classOrder []string
+	if opts.CSSClasses {
+		for _, row := range rows {
+			for _, r := range row {
+				if r.fg == nil {
+					continue
+				}
+				hex := hexColor(r.fg)
+				if !seen[hex] {
+					seen[hex] = true
+					classOrder = append(classOrder, hex)
+				}
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	var style strings.Builder
+	fmt.Fprintf(&style, "font-family:%s;font-size:%dpx;line-height:1.2;", html.EscapeString(fontFamily(opts)), fontSize(opts))
+	if bg, fg := documentColors(opts.Background); bg != nil {
+		fmt.Fprintf(&style, "background:%s;color:%s;", hexColor(bg), hexColor(fg))
+	}
+
+	if !opts.Fragment {
+		bw.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>asciify</title>\n")
+	}
+	if len(classOrder) > 0 {
+		bw.WriteString("<style>\n")
+		for _, hex := range classOrder {
+			fmt.Fprintf(bw, ".asciify .%s{color:%s}\n", colorClass(hex), hex)
+		}
+		bw.WriteString("</style>\n")
+	}
+	if !opts.Fragment {
+		bw.WriteString("</head>\n<body>\n")
+	}
+
+	fmt.Fprintf(bw, "<pre class=\"asciify\" style=\"%s\">", style.String())
+	for y, row := range rows {
+		for _, r := range row {
+			text := html.EscapeString(r.text)
+			var class string
+			var styles []string
+			if r.fg != nil {
+				if opts.CSSClasses {
+					class = colorClass(hexColor(r.fg))
+				} else {
+					styles = append(styles, "color:"+hexColor(r.fg))
+				}
+			}
+			if r.bg != nil {
+				styles = append(styles, "background:"+hexColor(r.bg))
+			}
+
+			if class == "" && len(styles) == 0 {
+				bw.WriteString(text)
+				continue
+			}
+			bw.WriteString("<span")
+			if class != "" {
+				fmt.Fprintf(bw, " class=\"%s\"", class)
+			}
+			if len(styles) > 0 {
+				fmt.Fprintf(bw, " style=\"%s\"", strings.Join(styles, ";"))
+			}
+			fmt.Fprintf(bw, ">%s</span>", text)
+		}
+		if y < len(rows)-1 {
+			bw.WriteByte('\n')
+		}
+	}
+	bw.WriteString("</pre>\n")
+
+	if !opts.Fragment {
+		bw.WriteString("</body>\n</html>\n")
+	}
+	return bw.Flush()
+}
+
+// colorClass returns the CSS class for a "#rrggbb" colour.
+func colorClass(hex string) string {
+	return "c" + strings.TrimPrefix(hex, "#")
+}
+
+// colorRuns splits every row of the frame into runs of equal colours.
+// Blank cells take the foreground of the run they are in, since it is
+// invisible anyway; only their background has to match.
+func colorRuns(f *frame.Frame, useColor bool) [][]run {
+	rows := make([][]run, f.Height)
+	for y := 0; y < f.Height; y++ {
+		var runs []run
+		var text []rune
+		var cur run
+		started := false
+
+		for x := 0; x < f.Width; x++ {
+			ch := f.Get(x, y)
+			var fg, bg color.Color
+			if ch == 0 || f.IsTransparent(x, y) {
+				ch = ' '
+			} else if useColor {
+				fg = f.GetColor(x, y)
+				bg = f.GetBackground(x, y)
+			}
+
+			if ch == ' ' && started && sameColor(bg, cur.bg) {
+				text = append(text, ch)
+				continue
+			}
+			if started && (!sameColor(fg, cur.fg) || !sameColor(bg, cur.bg)) {
+				cur.text = string(text)
+				runs = append(runs, cur)
+				text = text[:0]
+				started = false
+			}
+			if !started {
+				cur = run{x: x, fg: fg, bg: bg}
+				started = true
+			}
+			text = append(text, ch)
+		}
+		if started {
+			cur.text = string(text)
+			if cur.bg == nil {
+				cur.text = strings.TrimRight(cur.text, " ")
+			}
+			runs = append(runs, cur)
+		}
+		rows[y] = runs
+	}
+	return rows
+}
+
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return ar>>8 == br>>8 && ag>>8 == bg>>8 && ab>>8 == bb>>8
+}
+
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// documentColors returns the page background and default text colour for
+// a -bg setting, or nil when the document should inherit them.
+func documentColors(bg terminal.BackgroundColor) (color.Color, color.Color) {
+	switch bg {
+	case terminal.BgBlack:
+		return color.RGBA{0x00, 0x00, 0x00, 0xff}, color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+	case terminal.BgWhite:
+		return color.RGBA{0xff, 0xff, 0xff, 0xff}, color.RGBA{0x00, 0x00, 0x00, 0xff}
+	}
+	return nil, nil
+}
+
+func fontFamily(opts Options) string {
+	if opts.FontFamily != "" {
+		return opts.FontFamily
+	}
+	return defaultFontFamily
+}
+
+func fontSize(opts Options) int {
+	if opts.FontSize > 0 {
+		return opts.FontSize
+	}
+	return defaultFontSize
+}
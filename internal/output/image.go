@@ -0,0 +1,95 @@
+package output
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/palette"
+	"github.com/kozmaoliver/asciify/internal/raster"
+)
+
+// WritePNG rasterises the frame with the embedded bitmap font and writes
+// it as a PNG image.
+func WritePNG(w io.Writer, f *frame.Frame, opts Options) error {
+	return png.Encode(w, rasterize(f, opts))
+}
+
+// WriteGIF rasterises the frames with the embedded bitmap font and writes
+// them as an animated GIF that loops forever, keeping the frame delays.
+// Frames of different sizes are drawn onto a canvas as large as the biggest
+// one. The palette is built from the colours actually used; if there are
+// more than 256 it is reduced with median cut.
+func WriteGIF(w io.Writer, frames []*frame.Frame, delays []time.Duration, opts Options) error {
+	images := make([]*image.RGBA, len(frames))
+	var canvas image.Rectangle
+	for i, f := range frames {
+		images[i] = rasterize(f, opts)
+		canvas = canvas.Union(images[i].Bounds())
+	}
+
+	var bg color.Color = raster.DefaultBackground
+	if c, _ := documentColors(opts.Background); c != nil {
+		bg = c
+	}
+	histogram := make(map[color.RGBA]int)
+	for i, img := range images {
+		if img.Bounds() != canvas {
+			padded := image.NewRGBA(canvas)
+			draw.Draw(padded, canvas, &image.Uniform{bg}, image.Point{}, draw.Src)
+			draw.Draw(padded, img.Bounds(), img, img.Bounds().Min, draw.Src)
+			images[i] = padded
+		}
+		pix := images[i].Pix
+		for p := 0; p < len(pix); p += 4 {
+			histogram[color.RGBA{pix[p], pix[p+1], pix[p+2], 0xff}]++
+		}
+	}
+	pal := palette.MedianCut(histogram, 256)
+
+	gifPalette := make(color.Palette, len(pal.Colors))
+	for i, c := range pal.Colors {
+		gifPalette[i] = c
+	}
+
+	cache := make(map[color.RGBA]uint8)
+	g := &gif.GIF{
+		Config: image.Config{
+			ColorModel: gifPalette,
+			Width:      canvas.Dx(),
+			Height:     canvas.Dy(),
+		},
+	}
+	for i, img := range images {
+		paletted := image.NewPaletted(img.Bounds(), gifPalette)
+		for p, q := 0, 0; p < len(img.Pix); p, q = p+4, q+1 {
+			c := color.RGBA{img.Pix[p], img.Pix[p+1], img.Pix[p+2], 0xff}
+			idx, ok := cache[c]
+			if !ok {
+				idx = uint8(pal.Index(c))
+				cache[c] = idx
+			}
+			paletted.Pix[q] = idx
+		}
+
+		g.Image = append(g.Image, paletted)
+		// GIF delays are in hundredths of a second.
+		g.Delay = append(g.Delay, int(frameDelay(delays, i)/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(w, g)
+}
+
+func rasterize(f *frame.Frame, opts Options) *image.RGBA {
+	bg, fg := documentColors(opts.Background)
+	return raster.Render(f, raster.Options{
+		Scale:      opts.Scale,
+		Background: bg,
+		Foreground: fg,
+		Color:      opts.Color,
+	})
+}
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/palette"
+)
+
+// mIRC formatting control characters.
+const (
+	ircColor = "\x03"
+	ircBold  = "\x02"
+	ircReset = "\x0f"
+)
+
+// ircPalette holds the 16 classic mIRC colours followed by the 83
+// extended colours (16-98) supported by modern clients.
+var ircPalette = newIRCPalette()
+
+func newIRCPalette() *palette.Palette {
+	hexes := []string{
+		"ffffff", "000000", "00007f", "009300", "ff0000", "7f0000", "9c009c", "fc7f00",
+		"ffff00", "00fc00", "009393", "00ffff", "0000fc", "ff00ff", "7f7f7f", "d2d2d2",
+
+		"470000", "472100", "474700", "324700", "004700", "00472c", "004747", "002747", "000047", "2e0047", "470047", "47002a",
+		"740000", "743a00", "747400", "517400", "007400", "007449", "007474", "004074", "000074", "4b0074", "740074", "740045",
+		"b50000", "b56300", "b5b500", "7db500", "00b500", "00b571", "00b5b5", "0063b5", "0000b5", "7500b5", "b500b5", "b5006b",
+		"ff0000", "ff8c00", "ffff00", "b2ff00", "00ff00", "00ffa0", "00ffff", "008cff", "0000ff", "a500ff", "ff00ff", "ff0098",
+		"ff5959", "ffb459", "ffff71", "cfff60", "6fff6f", "65ffc9", "6dffff", "59b4ff", "5959ff", "c459ff", "ff66ff", "ff59bc",
+		"ff9c9c", "ffd39c", "ffff9c", "e2ff9c", "9cff9c", "9cffdb", "9cffff", "9cd3ff", "9c9cff", "dc9cff", "ff9cff", "ff94d3",
+		"000000", "131313", "282828", "363636", "4d4d4d", "656565", "818181", "9f9f9f", "bcbcbc", "e2e2e2", "ffffff",
+	}
+	colors := make([]color.RGBA, len(hexes))
+	for i, h := range hexes {
+		colors[i], _ = palette.ParseHex(h)
+	}
+	return palette.New("mirc", colors)
+}
+
+// WriteIRC writes the frame with mIRC colour codes, one line per row, ready
+// to be sent to an IRC channel. Colours are quantised to the 16 standard
+// mIRC colours, or to all 99 with opts.IRCExtended.
+func WriteIRC(w io.Writer, f *frame.Frame, opts Options) error {
+	limit := 16
+	if opts.IRCExtended {
+		limit = 99
+	}
+	cache := make(map[color.RGBA]int)
+	index := func(c color.Color) int {
+		key := palette.ToRGBA(c)
+		if i, ok := cache[key]; ok {
+			return i
+		}
+		i := ircPalette.IndexIn(key, 0, limit)
+		cache[key] = i
+		return i
+	}
+
+	// Quantise first so neighbouring cells that map to the same mIRC
+	// colour share one colour code.
+	quantized := *f
+	quantize := func(c color.Color) color.Color { return ircPalette.Colors[index(c)] }
+	quantized.Colors = mapColors(f.Colors, quantize)
+	quantized.Backgrounds = mapColors(f.Backgrounds, quantize)
+
+	bw := bufio.NewWriter(w)
+	for _, row := range colorRuns(&quantized, opts.Color) {
+		colored := false
+		for _, r := range row {
+			switch {
+			case r.fg == nil && r.bg == nil:
+				if colored {
+					bw.WriteString(ircReset)
+					colored = false
+				}
+			case r.bg == nil:
+				fmt.Fprintf(bw, "%s%02d", ircColor, index(r.fg))
+				colored = true
+			default:
+				fg := 1
+				if r.fg != nil {
+					fg = index(r.fg)
+				}
+				fmt.Fprintf(bw, "%s%02d,%02d", ircColor, fg, index(r.bg))
+				colored = true
+			}
+			// A comma right after a colour code would be read as the start
+			// of a background colour; an empty bold toggle separates them.
+			if colored && strings.HasPrefix(r.text, ",") {
+				bw.WriteString(ircBold + ircBold)
+			}
+			bw.WriteString(r.text)
+		}
+		if colored {
+			bw.WriteString(ircReset)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// WriteBBCode writes the frame as forum markup with a [color] tag per
+// colour run. Paste it into a monospace block of the forum.
+func WriteBBCode(w io.Writer, f *frame.Frame, opts Options) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range colorRuns(f, opts.Color) {
+		for _, r := range row {
+			text := escapeBBCode(r.text)
+			if r.fg == nil {
+				bw.WriteString(text)
+				continue
+			}
+			fmt.Fprintf(bw, "[color=%s]%s[/color]", hexColor(r.fg), text)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// escapeBBCode keeps brackets in the art from forming tags. BBCode has no
+// escape character, so every "[" is followed by an empty bold tag, which
+// renders as nothing but stops "[b]" or "[url]" from being recognised.
+func escapeBBCode(s string) string {
+	return strings.ReplaceAll(s, "[", "[[b][/b]")
+}
+
+// mapColors returns a copy of a colour grid with fn applied to every colour.
+func mapColors(grid [][]color.Color, fn func(color.Color) color.Color) [][]color.Color {
+	if grid == nil {
+		return nil
+	}
+	out := make([][]color.Color, len(grid))
+	for y, row := range grid {
+		out[y] = make([]color.Color, len(row))
+		for x, c := range row {
+			if c != nil {
+				out[y][x] = fn(c)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+func TestEscapeBBCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"[b]", "[[b][/b]b]"},
+		{"[url]x[/url]", "[[b][/b]url]x[[b][/b]/url]"},
+		{"]]", "]]"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeBBCode(tt.in); got != tt.want {
+			t.Errorf("escapeBBCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteBBCode(t *testing.T) {
+	f := frame.New(4, 1)
+	f.EnableColors()
+	for x, ch := range []rune("[b]#") {
+		f.Set(x, 0, ch)
+	}
+	f.SetColor(3, 0, color.RGBA{0xff, 0x00, 0x00, 0xff})
+
+	var buf bytes.Buffer
+	if err := WriteBBCode(&buf, f, Options{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "[[b][/b]b][color=#ff0000]#[/color]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteBBCode() = %q, want %q", got, want)
+	}
+}
+
+func TestIRCColorMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		fg, bg   color.Color
+		extended bool
+		want     string
+	}{
+		{"white", color.RGBA{0xff, 0xff, 0xff, 0xff}, nil, false, "\x0300#\x0f\n"},
+		{"red", color.RGBA{0xf0, 0x10, 0x10, 0xff}, nil, false, "\x0304#\x0f\n"},
+		{"navy", color.RGBA{0x00, 0x00, 0x80, 0xff}, nil, false, "\x0302#\x0f\n"},
+		{"dark orange standard", color.RGBA{0x74, 0x3a, 0x00, 0xff}, nil, false, "\x0305#\x0f\n"},
+		{"dark orange extended", color.RGBA{0x74, 0x3a, 0x00, 0xff}, nil, true, "\x0329#\x0f\n"},
+		{"background only", nil, color.RGBA{0x00, 0x00, 0xfc, 0xff}, false, "\x0301,12#\x0f\n"},
+		{"foreground and background", color.RGBA{0xff, 0xff, 0x00, 0xff}, color.RGBA{0, 0, 0, 0xff}, false, "\x0308,01#\x0f\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := frame.New(1, 1)
+			f.Set(0, 0, '#')
+			if tt.fg != nil {
+				f.EnableColors()
+				f.SetColor(0, 0, tt.fg)
+			}
+			if tt.bg != nil {
+				f.EnableBackgrounds()
+				f.SetBackground(0, 0, tt.bg)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteIRC(&buf, f, Options{Color: true, IRCExtended: tt.extended}); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteIRC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteIRCMergesRuns(t *testing.T) {
+	f := frame.New(4, 1)
+	f.EnableColors()
+	for x, ch := range []rune("ab,c") {
+		f.Set(x, 0, ch)
+	}
+	// Two reds that map to the same mIRC colour share one code; the comma
+	// after the blue code is separated by an empty bold toggle.
+	f.SetColor(0, 0, color.RGBA{0xff, 0x00, 0x00, 0xff})
+	f.SetColor(1, 0, color.RGBA{0xf8, 0x08, 0x08, 0xff})
+	f.SetColor(2, 0, color.RGBA{0x00, 0x00, 0xfc, 0xff})
+	f.SetColor(3, 0, color.RGBA{0x00, 0x00, 0xfc, 0xff})
+
+	var buf bytes.Buffer
+	if err := WriteIRC(&buf, f, Options{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\x0304ab\x0312\x02\x02,c\x0f\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteIRC() = %q, want %q", got, want)
+	}
+}
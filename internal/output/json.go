@@ -0,0 +1,167 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/palette"
+)
+
+// Metadata describes how a frame was produced.
+type Metadata struct {
+	Theme      string      `json:"theme,omitempty"`
+	EdgeCutoff float64     `json:"edgeCutoff"`
+	Source     *Dimensions `json:"source,omitempty"`
+
+	// Timings holds the duration of pipeline stages in milliseconds.
+	Timings map[string]float64 `json:"timings,omitempty"`
+}
+
+// Dimensions is the size of the source image in pixels.
+type Dimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// jsonFrame is the JSON representation of a frame. Colour grids hold
+// "#rrggbb" strings, with "" for cells without a colour. Transparent marks
+// the transparent cells, which appear as spaces in Rows; it is omitted
+// when there are none.
+type jsonFrame struct {
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	Rows        []string   `json:"rows"`
+	Transparent [][]bool   `json:"transparent,omitempty"`
+	Colors      [][]string `json:"colors,omitempty"`
+	Backgrounds [][]string `json:"backgrounds,omitempty"`
+	Metadata    *Metadata  `json:"metadata,omitempty"`
+}
+
+// WriteJSON writes the frame as a JSON document. Colours are included when
+// opts.Color is set; transparent cells are written as spaces and listed in
+// the transparency mask.
+func WriteJSON(w io.Writer, f *frame.Frame, opts Options) error {
+	doc := jsonFrame{
+		Width:    f.Width,
+		Height:   f.Height,
+		Rows:     make([]string, f.Height),
+		Metadata: opts.Metadata,
+	}
+
+	line := make([]rune, f.Width)
+	transparent := false
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			ch := f.Get(x, y)
+			if ch == 0 {
+				ch = ' '
+			}
+			line[x] = ch
+			transparent = transparent || f.IsTransparent(x, y)
+		}
+		doc.Rows[y] = string(line)
+	}
+	if transparent {
+		doc.Transparent = f.Transparent
+	}
+
+	if opts.Color {
+		doc.Colors = hexGrid(f, f.Colors, f.GetColor)
+		doc.Backgrounds = hexGrid(f, f.Backgrounds, f.GetBackground)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func hexGrid(f *frame.Frame, grid [][]color.Color, get func(x, y int) color.Color) [][]string {
+	if grid == nil {
+		return nil
+	}
+	out := make([][]string, f.Height)
+	for y := range out {
+		out[y] = make([]string, f.Width)
+		for x := range out[y] {
+			if c := get(x, y); c != nil && !f.IsTransparent(x, y) {
+				out[y][x] = hexColor(c)
+			}
+		}
+	}
+	return out
+}
+
+// ReadJSON reads a frame written by WriteJSON.
+func ReadJSON(r io.Reader) (*frame.Frame, *Metadata, error) {
+	var doc jsonFrame
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Width < 0 || doc.Height < 0 || len(doc.Rows) != doc.Height {
+		return nil, nil, fmt.Errorf("frame has %d rows, expected height %d", len(doc.Rows), doc.Height)
+	}
+
+	f := frame.New(doc.Width, doc.Height)
+	for y, row := range doc.Rows {
+		x := 0
+		for _, ch := range row {
+			f.Set(x, y, ch)
+			x++
+		}
+		// Rows may have had trailing spaces trimmed by hand.
+		for ; x < doc.Width; x++ {
+			f.Set(x, y, ' ')
+		}
+	}
+	for y, row := range doc.Transparent {
+		for x, transparent := range row {
+			if transparent {
+				f.SetTransparent(x, y)
+			}
+		}
+	}
+
+	if doc.Colors != nil {
+		f.EnableColors()
+		if err := readHexGrid(doc.Colors, f.SetColor); err != nil {
+			return nil, nil, fmt.Errorf("colors: %w", err)
+		}
+	}
+	if doc.Backgrounds != nil {
+		f.EnableBackgrounds()
+		if err := readHexGrid(doc.Backgrounds, f.SetBackground); err != nil {
+			return nil, nil, fmt.Errorf("backgrounds: %w", err)
+		}
+	}
+	return f, doc.Metadata, nil
+}
+
+func readHexGrid(grid [][]string, set func(x, y int, c color.Color)) error {
+	for y, row := range grid {
+		for x, hex := range row {
+			if hex == "" {
+				continue
+			}
+			c, err := palette.ParseHex(hex)
+			if err != nil {
+				return err
+			}
+			set(x, y, c)
+		}
+	}
+	return nil
+}
+
+// ReadJSONFile reads a frame from a JSON file.
+func ReadJSONFile(path string) (*frame.Frame, *Metadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return ReadJSON(file)
+}
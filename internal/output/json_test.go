@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bytes"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	blue := color.RGBA{0x00, 0x00, 0xff, 0xff}
+
+	plain := frame.New(3, 2)
+	for y := 0; y < 2; y++ {
+		for x, ch := range []rune("a█ ") {
+			plain.Set(x, y, ch)
+		}
+	}
+
+	colored := frame.New(2, 1)
+	colored.EnableColors()
+	colored.EnableBackgrounds()
+	colored.Set(0, 0, '#')
+	colored.SetColor(0, 0, red)
+	colored.SetBackground(0, 0, blue)
+	colored.Set(1, 0, '.')
+
+	transparent := frame.New(3, 1)
+	transparent.Set(0, 0, 'x')
+	transparent.SetTransparent(1, 0)
+	transparent.Set(2, 0, 'y')
+
+	tests := []struct {
+		name     string
+		frame    *frame.Frame
+		color    bool
+		metadata *Metadata
+	}{
+		{"plain", plain, false, nil},
+		{"colors", colored, true, nil},
+		{"transparent", transparent, false, nil},
+		{"zero edge cutoff", plain, false, &Metadata{Theme: "default", EdgeCutoff: 0, Source: &Dimensions{Width: 10, Height: 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, tt.frame, Options{Color: tt.color, Metadata: tt.metadata}); err != nil {
+				t.Fatal(err)
+			}
+			got, meta, err := ReadJSON(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Cells, tt.frame.Cells) {
+				t.Errorf("Cells = %q, want %q", got.Cells, tt.frame.Cells)
+			}
+			for y := 0; y < tt.frame.Height; y++ {
+				for x := 0; x < tt.frame.Width; x++ {
+					if got.IsTransparent(x, y) != tt.frame.IsTransparent(x, y) {
+						t.Errorf("IsTransparent(%d, %d) = %v", x, y, got.IsTransparent(x, y))
+					}
+					if !sameColor(got.GetColor(x, y), tt.frame.GetColor(x, y)) {
+						t.Errorf("GetColor(%d, %d) = %v, want %v", x, y, got.GetColor(x, y), tt.frame.GetColor(x, y))
+					}
+					if !sameColor(got.GetBackground(x, y), tt.frame.GetBackground(x, y)) {
+						t.Errorf("GetBackground(%d, %d) = %v, want %v", x, y, got.GetBackground(x, y), tt.frame.GetBackground(x, y))
+					}
+				}
+			}
+			if !reflect.DeepEqual(meta, tt.metadata) {
+				t.Errorf("Metadata = %+v, want %+v", meta, tt.metadata)
+			}
+		})
+	}
+}
+
+func TestWriteJSONFields(t *testing.T) {
+	f := frame.New(1, 1)
+	f.Set(0, 0, 'a')
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, f, Options{Metadata: &Metadata{}}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"edgeCutoff": 0`) {
+		t.Errorf("zero edge cutoff omitted:\n%s", out)
+	}
+	if strings.Contains(out, "transparent") {
+		t.Errorf("transparency mask written for an opaque frame:\n%s", out)
+	}
+}
+
+func TestReadJSONErrors(t *testing.T) {
+	tests := []string{
+		`{"width": 2, "height": 2, "rows": ["ab"]}`,
+		`{"width": 1, "height": 1, "rows": ["a"], "colors": [["red"]]}`,
+		`{"width": 1, "height": 1, "rows": ["a"], "backgrounds": [["#12"]]}`,
+		`not json`,
+	}
+	for _, in := range tests {
+		if _, _, err := ReadJSON(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadJSON(%s) succeeded, want error", in)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+// Format identifies an output file format.
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatANSI   Format = "ansi"
+	FormatHTML   Format = "html"
+	FormatSVG    Format = "svg"
+	FormatPNG    Format = "png"
+	FormatGIF    Format = "gif"
+	FormatCast   Format = "cast"
+	FormatJSON   Format = "json"
+	FormatIRC    Format = "irc"
+	FormatBBCode Format = "bbcode"
+)
+
+// Formats lists the supported output formats.
+var Formats = []Format{FormatText, FormatANSI, FormatHTML, FormatSVG, FormatPNG, FormatGIF, FormatCast, FormatJSON, FormatIRC, FormatBBCode}
+
+var extensions = map[string]Format{
+	".txt":    FormatText,
+	".asc":    FormatText,
+	".ans":    FormatANSI,
+	".html":   FormatHTML,
+	".htm":    FormatHTML,
+	".svg":    FormatSVG,
+	".png":    FormatPNG,
+	".gif":    FormatGIF,
+	".cast":   FormatCast,
+	".json":   FormatJSON,
+	".irc":    FormatIRC,
+	".bbcode": FormatBBCode,
+	".bb":     FormatBBCode,
+}
+
+// ParseFormat parses a -format flag value.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if Format(strings.ToLower(s)) == format {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q", s)
+}
+
+// FormatFromPath selects the output format from a file extension,
+// falling back to plain text.
+func FormatFromPath(path string) Format {
+	if format, ok := extensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return format
+	}
+	return FormatText
+}
+
+// Options controls how a frame is written.
+type Options struct {
+	Background terminal.BackgroundColor
+	Color      bool
+	Depth      terminal.ColorDepth
+
+	// Sauce, if set, appends a SAUCE metadata record to ANSI output.
+	Sauce *Sauce
+
+	// Document options for HTML and SVG output.
+	FontFamily string
+	FontSize   int  // in pixels
+	Fragment   bool // bare <pre> block instead of a full document
+	CSSClasses bool // colour classes in a <style> block instead of inline styles
+
+	// Scale magnifies PNG and GIF output by an integer factor.
+	Scale int
+
+	// Metadata is embedded in JSON output.
+	Metadata *Metadata
+
+	// IRCExtended uses all 99 mIRC colours instead of the standard 16.
+	IRCExtended bool
+}
+
+// Animated reports whether the format can hold several frames.
+func (f Format) Animated() bool {
+	return f == FormatCast || f == FormatGIF
+}
+
+// Write writes a frame to w in the given format.
+func Write(w io.Writer, f *frame.Frame, format Format, opts Options) error {
+	return WriteAnimation(w, []*frame.Frame{f}, nil, format, opts)
+}
+
+// WriteAnimation writes a sequence of frames to w. Formats that hold a
+// single image get the first frame.
+func WriteAnimation(w io.Writer, frames []*frame.Frame, delays []time.Duration, format Format, opts Options) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to write")
+	}
+
+	f := frames[0]
+	switch format {
+	case FormatText:
+		return WriteText(w, f)
+	case FormatANSI:
+		return WriteANSI(w, f, opts)
+	case FormatHTML:
+		return WriteHTML(w, f, opts)
+	case FormatSVG:
+		return WriteSVG(w, f, opts)
+	case FormatPNG:
+		return WritePNG(w, f, opts)
+	case FormatGIF:
+		return WriteGIF(w, frames, delays, opts)
+	case FormatCast:
+		return WriteCast(w, frames, delays, opts)
+	case FormatJSON:
+		return WriteJSON(w, f, opts)
+	case FormatIRC:
+		return WriteIRC(w, f, opts)
+	case FormatBBCode:
+		return WriteBBCode(w, f, opts)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+// WriteFile writes a frame to the file at path. A path of "-" writes to stdout.
+func WriteFile(path string, f *frame.Frame, format Format, opts Options) error {
+	return WriteAnimationFile(path, []*frame.Frame{f}, nil, format, opts)
+}
+
+// WriteAnimationFile writes a sequence of frames to the file at path.
+// A path of "-" writes to stdout.
+func WriteAnimationFile(path string, frames []*frame.Frame, delays []time.Duration, format Format, opts Options) error {
+	if path == "-" {
+		return WriteAnimation(os.Stdout, frames, delays, format, opts)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteAnimation(file, frames, delays, format, opts); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// CanRead reports whether a frame can be read back from the file at path.
+func CanRead(path string) bool {
+	format := FormatFromPath(path)
+	return format == FormatJSON || format == FormatANSI
+}
+
+// ReadFile reads a previously exported frame back from a file.
+func ReadFile(path string) (*frame.Frame, error) {
+	switch FormatFromPath(path) {
+	case FormatJSON:
+		f, _, err := ReadJSONFile(path)
+		return f, err
+	case FormatANSI:
+		return ReadANSIFile(path)
+	}
+	return nil, fmt.Errorf("%s: cannot read frames from this format", path)
+}
+
+// WriteText writes the frame as plain UTF-8 text without escape sequences.
+// Trailing spaces are trimmed from every line.
+func WriteText(w io.Writer, f *frame.Frame) error {
+	bw := bufio.NewWriter(w)
+	line := make([]rune, f.Width)
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			ch := f.Get(x, y)
+			if ch == 0 {
+				ch = ' '
+			}
+			line[x] = ch
+		}
+		bw.WriteString(strings.TrimRight(string(line), " "))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
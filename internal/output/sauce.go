@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Sauce holds the metadata of a SAUCE record, the de-facto standard for
+// describing ANSI art files (https://www.acid.org/info/sauce/sauce.htm).
+type Sauce struct {
+	Title  string
+	Author string
+	Group  string
+	Date   time.Time
+}
+
+const (
+	sauceDataTypeCharacter = 1
+	sauceFileTypeANSI      = 1
+)
+
+// writeSauce appends the EOF marker and a 128 byte SAUCE record describing
+// an ANSI file of the given data size and dimensions.
+func writeSauce(w io.Writer, s *Sauce, fileSize int64, width, height int) error {
+	var buf bytes.Buffer
+	buf.WriteByte(0x1a) // EOF marker; SAUCE-aware readers stop here
+	buf.WriteString("SAUCE00")
+	writePadded(&buf, s.Title, 35)
+	writePadded(&buf, s.Author, 20)
+	writePadded(&buf, s.Group, 20)
+
+	date := s.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	buf.WriteString(date.Format("20060102"))
+
+	binary.Write(&buf, binary.LittleEndian, uint32(fileSize))
+	buf.WriteByte(sauceDataTypeCharacter)
+	buf.WriteByte(sauceFileTypeANSI)
+	binary.Write(&buf, binary.LittleEndian, uint16(width))
+	binary.Write(&buf, binary.LittleEndian, uint16(height))
+	binary.Write(&buf, binary.LittleEndian, uint16(0)) // TInfo3
+	binary.Write(&buf, binary.LittleEndian, uint16(0)) // TInfo4
+	buf.WriteByte(0)                                   // comment lines
+	buf.WriteByte(0)                                   // flags
+	buf.Write(make([]byte, 22))                        // font name
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writePadded writes s encoded as CP437, truncated or space-padded to
+// exactly n characters.
+func writePadded(buf *bytes.Buffer, s string, n int) {
+	b := encodeCP437(s)
+	if len(b) > n {
+		b = b[:n]
+	}
+	buf.Write(b)
+	for i := len(b); i < n; i++ {
+		buf.WriteByte(' ')
+	}
+}
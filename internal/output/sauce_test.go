@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+)
+
+func TestCP437Table(t *testing.T) {
+	if len(cp437) != 128 {
+		t.Fatalf("cp437 has %d entries, want 128", len(cp437))
+	}
+	seen := make(map[rune]bool)
+	for _, r := range cp437 {
+		if seen[r] {
+			t.Errorf("%q appears twice", r)
+		}
+		seen[r] = true
+	}
+}
+
+func TestEncodeCP437(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []byte
+	}{
+		{"plain", []byte("plain")},
+		{"\x1b[0m\n", []byte("\x1b[0m\n")},
+		{"Ç█░▒▓", []byte{0x80, 0xdb, 0xb0, 0xb1, 0xb2}},
+		{"é±", []byte{0x82, 0xf1}},
+		{"日本", []byte("??")},
+	}
+	for _, tt := range tests {
+		if got := encodeCP437(tt.in); !bytes.Equal(got, tt.want) {
+			t.Errorf("encodeCP437(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSauce(t *testing.T) {
+	tests := []struct {
+		name                 string
+		sauce                Sauce
+		title, author, group string
+	}{
+		{
+			name:   "padded",
+			sauce:  Sauce{Title: "Art", Author: "me", Group: "grp"},
+			title:  "Art" + strings.Repeat(" ", 32),
+			author: "me" + strings.Repeat(" ", 18),
+			group:  "grp" + strings.Repeat(" ", 17),
+		},
+		{
+			name:   "truncated",
+			sauce:  Sauce{Title: strings.Repeat("t", 40), Author: strings.Repeat("a", 25), Group: strings.Repeat("g", 21)},
+			title:  strings.Repeat("t", 35),
+			author: strings.Repeat("a", 20),
+			group:  strings.Repeat("g", 20),
+		},
+		{
+			// Each character is one CP437 byte, so multi-byte UTF-8 is
+			// truncated by characters and never split.
+			name:   "cp437",
+			sauce:  Sauce{Title: strings.Repeat("é", 36), Author: "Ç日", Group: "░"},
+			title:  strings.Repeat("\x82", 35),
+			author: "\x80?" + strings.Repeat(" ", 18),
+			group:  "\xb0" + strings.Repeat(" ", 19),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.sauce.Date = time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+			var buf bytes.Buffer
+			if err := writeSauce(&buf, &tt.sauce, 1234, 80, 25); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.Bytes()
+			if len(out) != 129 || out[0] != 0x1a {
+				t.Fatalf("got %d bytes starting with %#x, want EOF marker and 128 byte record", len(out), out[0])
+			}
+			rec := out[1:]
+			checks := []struct {
+				field string
+				got   []byte
+				want  string
+			}{
+				{"ID", rec[0:7], "SAUCE00"},
+				{"Title", rec[7:42], tt.title},
+				{"Author", rec[42:62], tt.author},
+				{"Group", rec[62:82], tt.group},
+				{"Date", rec[82:90], "20240309"},
+			}
+			for _, c := range checks {
+				if string(c.got) != c.want {
+					t.Errorf("%s = %q, want %q", c.field, c.got, c.want)
+				}
+			}
+			if size := binary.LittleEndian.Uint32(rec[90:]); size != 1234 {
+				t.Errorf("FileSize = %d, want 1234", size)
+			}
+			if rec[94] != sauceDataTypeCharacter || rec[95] != sauceFileTypeANSI {
+				t.Errorf("DataType, FileType = %d, %d", rec[94], rec[95])
+			}
+			if w, h := binary.LittleEndian.Uint16(rec[96:]), binary.LittleEndian.Uint16(rec[98:]); w != 80 || h != 25 {
+				t.Errorf("TInfo1, TInfo2 = %d, %d; want 80, 25", w, h)
+			}
+		})
+	}
+}
+
+func TestWriteANSISauceEncoding(t *testing.T) {
+	f := frame.New(3, 1)
+	for x, ch := range []rune("█▒a") {
+		f.Set(x, 0, ch)
+	}
+
+	tests := []struct {
+		name  string
+		sauce *Sauce
+		body  string
+	}{
+		{"utf-8 without sauce", nil, "█▒a\n\x1b[0m"},
+		{"cp437 with sauce", &Sauce{}, "\xdb\xb1a\n\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := Options{Background: terminal.BgNone, Depth: terminal.DepthMono, Sauce: tt.sauce}
+			if err := WriteANSI(&buf, f, opts); err != nil {
+				t.Fatal(err)
+			}
+			body, _, _ := bytes.Cut(buf.Bytes(), []byte{0x1a})
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if tt.sauce != nil {
+				rec := buf.Bytes()[buf.Len()-128:]
+				if size := binary.LittleEndian.Uint32(rec[90:]); int(size) != len(body) {
+					t.Errorf("FileSize = %d, want %d", size, len(body))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+// Monospace cell metrics relative to the font size. Most monospace fonts
+// advance 0.6em per glyph; lines are spaced like the HTML output.
+const (
+	svgCellWidth  = 0.6
+	svgCellHeight = 1.2
+	svgBaseline   = 0.95 // baseline offset from the top of a cell
+)
+
+// WriteSVG writes the frame as an SVG image with one <text> element per
+// row and a <tspan> per colour run. Every run is pinned to its cell
+// position and stretched to its exact width, so the grid stays aligned
+// whatever monospace font the viewer substitutes. Cell backgrounds are
+// drawn as rectangles behind the text.
+func WriteSVG(w io.Writer, f *frame.Frame, opts Options) error {
+	size := float64(fontSize(opts))
+	cellW := size * svgCellWidth
+	cellH := size * svgCellHeight
+	rows := colorRuns(f, opts.Color)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		num(cellW*float64(f.Width)), num(cellH*float64(f.Height)), num(cellW*float64(f.Width)), num(cellH*float64(f.Height)))
+
+	bg, fg := documentColors(opts.Background)
+	if bg != nil {
+		fmt.Fprintf(bw, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", hexColor(bg))
+	}
+
+	// Cell backgrounds first so the text is painted on top.
+	for y, row := range rows {
+		for _, r := range row {
+			if r.bg == nil {
+				continue
+			}
+			width := len([]rune(r.text))
+			fmt.Fprintf(bw, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+				num(cellW*float64(r.x)), num(cellH*float64(y)), num(cellW*float64(width)), num(cellH), hexColor(r.bg))
+		}
+	}
+
+	fmt.Fprintf(bw, "<g font-family=\"%s\" font-size=\"%s\" xml:space=\"preserve\"", html.EscapeString(fontFamily(opts)), num(size))
+	if fg != nil {
+		fmt.Fprintf(bw, " fill=\"%s\"", hexColor(fg))
+	}
+	bw.WriteString(">\n")
+
+	for y, row := range rows {
+		var spans strings.Builder
+		for _, r := range row {
+			text := strings.TrimRight(r.text, " ")
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			width := len([]rune(text))
+			fmt.Fprintf(&spans, "<tspan x=\"%s\" textLength=\"%s\" lengthAdjust=\"spacing\"", num(cellW*float64(r.x)), num(cellW*float64(width)))
+			if r.fg != nil {
+				fmt.Fprintf(&spans, " fill=\"%s\"", hexColor(r.fg))
+			}
+			fmt.Fprintf(&spans, ">%s</tspan>", html.EscapeString(text))
+		}
+		if spans.Len() == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "<text y=\"%s\">%s</text>\n", num(cellH*float64(y)+size*svgBaseline), spans.String())
+	}
+
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}
+
+// num formats a coordinate with at most two decimals.
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,153 @@
+package palette
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Lookup returns the built-in palette with the given name, or loads the
+// palette file at that path.
+func Lookup(nameOrPath string) (*Palette, error) {
+	if p, ok := Named(nameOrPath); ok {
+		return p, nil
+	}
+	return Load(nameOrPath)
+}
+
+// Load reads a palette file. GIMP palettes (.gpl) and plain lists of hex
+// colours, one per line, are supported. Hex lists may contain blank lines
+// and comments starting with ";", "//" or "#".
+func Load(path string) (*Palette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	p, err := Parse(file, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse reads a palette in GIMP (.gpl) or hex list format.
+func Parse(r io.Reader, name string) (*Palette, error) {
+	scanner := bufio.NewScanner(r)
+	var colors []color.RGBA
+	gimp := false
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		if lineNo == 1 && line == "GIMP Palette" {
+			gimp = true
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if gimp {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			if key, value, ok := strings.Cut(line, ":"); ok && !strings.ContainsAny(key, "0123456789") {
+				if strings.TrimSpace(key) == "Name" {
+					name = strings.TrimSpace(value)
+				}
+				continue
+			}
+			c, err := parseGIMPLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			colors = append(colors, c)
+			continue
+		}
+
+		// In hex lists every line holds exactly one colour. Since '#' can
+		// prefix a colour, a '#' line is only a comment when the whole
+		// line is not one, so "#abc" is a colour but "#abc text" is not.
+		c, err := ParseHex(line)
+		if err != nil {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		colors = append(colors, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("palette has no colors")
+	}
+	return New(name, colors), nil
+}
+
+func parseGIMPLine(line string) (color.RGBA, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return color.RGBA{}, fmt.Errorf("expected \"R G B [name]\", got %q", line)
+	}
+
+	var rgb [3]uint8
+	for i := range rgb {
+		v, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid channel value %q", fields[i])
+		}
+		rgb[i] = uint8(v)
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, nil
+}
+
+// ParseHex parses a colour written as "#rrggbb", "rrggbb", "#rgb" or "rgb".
+func ParseHex(s string) (color.RGBA, error) {
+	h := strings.TrimPrefix(s, "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	if len(h) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, nil
+}
+
+var colorNames = map[string]string{
+	"black":   "000000",
+	"white":   "ffffff",
+	"gray":    "808080",
+	"red":     "ff0000",
+	"green":   "00ff00",
+	"blue":    "0000ff",
+	"yellow":  "ffff00",
+	"cyan":    "00ffff",
+	"magenta": "ff00ff",
+}
+
+// ParseColor parses a colour given as a hex value or one of a few basic
+// colour names (black, white, gray, red, green, blue, yellow, cyan, magenta).
+func ParseColor(s string) (color.RGBA, error) {
+	if hex, ok := colorNames[strings.ToLower(s)]; ok {
+		return ParseHex(hex)
+	}
+	return ParseHex(s)
+}
@@ -0,0 +1,106 @@
+package palette
+
+import (
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"#ff8000", color.RGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"0f380f", color.RGBA{0x0f, 0x38, 0x0f, 0xff}, false},
+		{"#abc", color.RGBA{0xaa, 0xbb, 0xcc, 0xff}, false},
+		{"FFF", color.RGBA{0xff, 0xff, 0xff, 0xff}, false},
+		{"#abcd", color.RGBA{}, true},
+		{"#gg0000", color.RGBA{}, true},
+		{"", color.RGBA{}, true},
+		{"#abc text", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHex(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseHex(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHex(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantName string
+		want     []color.RGBA
+		wantErr  bool
+	}{
+		{
+			name:     "hex list",
+			in:       "#000000\nffffff\n\n#f00\n",
+			wantName: "test",
+			want:     []color.RGBA{{0, 0, 0, 0xff}, {0xff, 0xff, 0xff, 0xff}, {0xff, 0, 0, 0xff}},
+		},
+		{
+			name:     "hex list comments",
+			in:       "; semicolon\n// slashes\n# hash\n#abc some comment\n  #123456  \n",
+			wantName: "test",
+			want:     []color.RGBA{{0x12, 0x34, 0x56, 0xff}},
+		},
+		{
+			name:    "hex list trailing text",
+			in:      "#000000\nffffff white\n",
+			wantErr: true,
+		},
+		{
+			name:    "hex list bad colour",
+			in:      "12345\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			in:      "# nothing here\n",
+			wantErr: true,
+		},
+		{
+			name:     "gimp",
+			in:       "GIMP Palette\nName: Mine\nColumns: 4\n# comment\n  0   0   0\tBlack\n255 128 0 Orange\n",
+			wantName: "Mine",
+			want:     []color.RGBA{{0, 0, 0, 0xff}, {0xff, 0x80, 0, 0xff}},
+		},
+		{
+			name:    "gimp bad channel",
+			in:      "GIMP Palette\n0 0 300\n",
+			wantErr: true,
+		},
+		{
+			name:    "gimp short line",
+			in:      "GIMP Palette\n0 0\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(strings.NewReader(tt.in), "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", p.Name, tt.wantName)
+			}
+			if !reflect.DeepEqual(p.Colors, tt.want) {
+				t.Errorf("Colors = %v, want %v", p.Colors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+)
+
+// Lab is a colour in the CIELAB colour space (D65 white point).
+// Euclidean distance between two Lab colours approximates perceived difference.
+type Lab struct {
+	L, A, B float64
+}
+
+// D65 reference white.
+const (
+	whiteX = 0.95047
+	whiteY = 1.00000
+	whiteZ = 1.08883
+)
+
+// ToLab converts a color to CIELAB.
+func ToLab(c color.Color) Lab {
+	r, g, b, _ := c.RGBA()
+
+	rl := linearize(float64(r>>8) / 255.0)
+	gl := linearize(float64(g>>8) / 255.0)
+	bl := linearize(float64(b>>8) / 255.0)
+
+	// sRGB -> XYZ
+	x := (0.4124564*rl + 0.3575761*gl + 0.1804375*bl) / whiteX
+	y := (0.2126729*rl + 0.7151522*gl + 0.0721750*bl) / whiteY
+	z := (0.0193339*rl + 0.1191920*gl + 0.9503041*bl) / whiteZ
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// DistanceSq returns the squared CIE76 colour difference between two colours.
+func (l Lab) DistanceSq(o Lab) float64 {
+	dl := l.L - o.L
+	da := l.A - o.A
+	db := l.B - o.B
+	return dl*dl + da*da + db*db
+}
+
+// Distance returns the CIE76 colour difference (delta E) between two colours.
+func (l Lab) Distance(o Lab) float64 {
+	return math.Sqrt(l.DistanceSq(o))
+}
+
+func linearize(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func labF(t float64) float64 {
+	const delta = 6.0 / 29.0
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29.0
+}
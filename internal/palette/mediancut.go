@@ -0,0 +1,109 @@
+package palette
+
+import (
+	"image/color"
+	"sort"
+)
+
+// MedianCut builds a palette of at most n colours that represents the
+// given colour histogram. When the histogram already has n colours or
+// fewer they are used as they are.
+func MedianCut(histogram map[color.RGBA]int, n int) *Palette {
+	colors := make([]color.RGBA, 0, len(histogram))
+	for c := range histogram {
+		colors = append(colors, c)
+	}
+	// Map iteration order is random; sort for reproducible output.
+	sort.Slice(colors, func(i, j int) bool { return packRGB(colors[i]) < packRGB(colors[j]) })
+
+	if len(colors) <= n {
+		return New("median-cut", colors)
+	}
+
+	boxes := [][]color.RGBA{colors}
+	for len(boxes) < n {
+		// Split the box with the widest channel range.
+		best, bestRange, bestChannel := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			ch, r := widestChannel(box)
+			if r > bestRange {
+				best, bestRange, bestChannel = i, r, ch
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		box := boxes[best]
+		sort.Slice(box, func(i, j int) bool { return channel(box[i], bestChannel) < channel(box[j], bestChannel) })
+
+		// Cut at the weighted median so busy colours get more entries.
+		total := 0
+		for _, c := range box {
+			total += histogram[c]
+		}
+		cut, acc := 1, 0
+		for i, c := range box[:len(box)-1] {
+			acc += histogram[c]
+			if acc*2 >= total {
+				cut = i + 1
+				break
+			}
+		}
+		boxes[best] = box[:cut]
+		boxes = append(boxes, box[cut:])
+	}
+
+	result := make([]color.RGBA, len(boxes))
+	for i, box := range boxes {
+		var r, g, b, total int
+		for _, c := range box {
+			w := histogram[c]
+			r += int(c.R) * w
+			g += int(c.G) * w
+			b += int(c.B) * w
+			total += w
+		}
+		if total == 0 {
+			total = 1
+		}
+		result[i] = color.RGBA{uint8(r / total), uint8(g / total), uint8(b / total), 0xff}
+	}
+	return New("median-cut", result)
+}
+
+func widestChannel(box []color.RGBA) (int, int) {
+	lo := [3]int{255, 255, 255}
+	hi := [3]int{}
+	for _, c := range box {
+		for ch := 0; ch < 3; ch++ {
+			v := channel(c, ch)
+			lo[ch] = min(lo[ch], v)
+			hi[ch] = max(hi[ch], v)
+		}
+	}
+	best := 0
+	for ch := 1; ch < 3; ch++ {
+		if hi[ch]-lo[ch] > hi[best]-lo[best] {
+			best = ch
+		}
+	}
+	return best, hi[best] - lo[best]
+}
+
+func channel(c color.RGBA, ch int) int {
+	switch ch {
+	case 0:
+		return int(c.R)
+	case 1:
+		return int(c.G)
+	}
+	return int(c.B)
+}
+
+func packRGB(c color.RGBA) uint32 {
+	return uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+}
@@ -0,0 +1,62 @@
+package palette
+
+import (
+	"image/color"
+	"sort"
+	"strings"
+)
+
+var named = map[string][]string{
+	// Original Game Boy (DMG) 4-shade green.
+	"gameboy": {"0f380f", "306230", "8bac0f", "9bbc0f"},
+	// IBM CGA full 16-colour palette.
+	"cga": {
+		"000000", "0000aa", "00aa00", "00aaaa", "aa0000", "aa00aa", "aa5500", "aaaaaa",
+		"555555", "5555ff", "55ff55", "55ffff", "ff5555", "ff55ff", "ffff55", "ffffff",
+	},
+	// Commodore 64 (Pepto's measurements).
+	"c64": {
+		"000000", "ffffff", "68372b", "70a4b2", "6f3d86", "588d43", "352879", "b8c76f",
+		"6f4f25", "433900", "9a6759", "444444", "6c6c6c", "9ad284", "6c5eb5", "959595",
+	},
+	// Sinclair ZX Spectrum, normal and bright (bright black is black).
+	"zxspectrum": {
+		"000000", "0000d7", "d70000", "d700d7", "00d700", "00d7d7", "d7d700", "d7d7d7",
+		"0000ff", "ff0000", "ff00ff", "00ff00", "00ffff", "ffff00", "ffffff",
+	},
+	// PICO-8 fantasy console.
+	"pico8": {
+		"000000", "1d2b53", "7e2553", "008751", "ab5236", "5f574f", "c2c3c7", "fff1e8",
+		"ff004d", "ffa300", "ffec27", "00e436", "29adff", "83769c", "ff77a8", "ffccaa",
+	},
+	// Solarized base tones and accents.
+	"solarized": {
+		"002b36", "073642", "586e75", "657b83", "839496", "93a1a1", "eee8d5", "fdf6e3",
+		"b58900", "cb4b16", "dc322f", "d33682", "6c71c4", "268bd2", "2aa198", "859900",
+	},
+}
+
+// Named returns one of the built-in palettes by name.
+func Named(name string) (*Palette, bool) {
+	hexes, ok := named[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+
+	colors := make([]color.RGBA, len(hexes))
+	for i, h := range hexes {
+		c, _ := ParseHex(h)
+		colors[i] = c
+	}
+	return New(strings.ToLower(name), colors), true
+}
+
+// Names returns the names of the built-in palettes in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+package palette
+
+import (
+	"image/color"
+)
+
+// Palette is a fixed set of colours that arbitrary colours can be mapped onto.
+// Matching is done by perceptual distance in CIELAB.
+type Palette struct {
+	Name   string
+	Colors []color.RGBA
+	labs   []Lab
+}
+
+func New(name string, colors []color.RGBA) *Palette {
+	labs := make([]Lab, len(colors))
+	for i, c := range colors {
+		labs[i] = ToLab(c)
+	}
+	return &Palette{
+		Name:   name,
+		Colors: colors,
+		labs:   labs,
+	}
+}
+
+// Index returns the index of the palette colour perceptually closest to c.
+func (p *Palette) Index(c color.Color) int {
+	return p.IndexIn(c, 0, len(p.Colors))
+}
+
+// IndexIn is like Index but only considers palette entries in [from, to).
+func (p *Palette) IndexIn(c color.Color, from, to int) int {
+	target := ToLab(c)
+	best := from
+	bestDist := -1.0
+	for i := from; i < to; i++ {
+		d := target.DistanceSq(p.labs[i])
+		if bestDist < 0 || d < bestDist {
+			best = i
+			bestDist = d
+		}
+	}
+	return best
+}
+
+// Convert returns the palette colour perceptually closest to c.
+func (p *Palette) Convert(c color.Color) color.Color {
+	if len(p.Colors) == 0 {
+		return c
+	}
+	return p.Colors[p.Index(c)]
+}
+
+// ToRGBA converts c to an opaque 8-bit colour, dropping any transparency.
+func ToRGBA(c color.Color) color.RGBA {
+	r, g, b, _ := c.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xff}
+}
@@ -0,0 +1,88 @@
+package palette
+
+import (
+	"image/color"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+// QuantizeFrame replaces every colour of the frame's colour grid with its
+// closest palette colour. With dither enabled the quantisation error is
+// spread to neighbouring cells using Floyd-Steinberg error diffusion.
+func QuantizeFrame(f *frame.Frame, p *Palette, dither bool) {
+	if f.Colors == nil || len(p.Colors) == 0 {
+		return
+	}
+
+	cache := make(map[color.RGBA]color.RGBA)
+	nearest := func(c color.RGBA) color.RGBA {
+		if q, ok := cache[c]; ok {
+			return q
+		}
+		q := p.Colors[p.Index(c)]
+		cache[c] = q
+		return q
+	}
+
+	if !dither {
+		for y := 0; y < f.Height; y++ {
+			for x := 0; x < f.Width; x++ {
+				if c := f.GetColor(x, y); c != nil {
+					f.SetColor(x, y, nearest(ToRGBA(c)))
+				}
+			}
+		}
+		return
+	}
+
+	// Working buffer of colours plus accumulated error, in 8-bit units.
+	type rgb struct{ r, g, b float64 }
+	buf := make([][]rgb, f.Height)
+	for y := range buf {
+		buf[y] = make([]rgb, f.Width)
+		for x := range buf[y] {
+			if c := f.GetColor(x, y); c != nil {
+				r, g, b, _ := c.RGBA()
+				buf[y][x] = rgb{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+			}
+		}
+	}
+
+	spread := func(x, y int, er, eg, eb, weight float64) {
+		if x < 0 || x >= f.Width || y >= f.Height || f.GetColor(x, y) == nil {
+			return
+		}
+		buf[y][x].r += er * weight
+		buf[y][x].g += eg * weight
+		buf[y][x].b += eb * weight
+	}
+
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			if f.GetColor(x, y) == nil {
+				continue
+			}
+			old := buf[y][x]
+			q := nearest(color.RGBA{clamp8(old.r), clamp8(old.g), clamp8(old.b), 0xff})
+			f.SetColor(x, y, q)
+
+			er := old.r - float64(q.R)
+			eg := old.g - float64(q.G)
+			eb := old.b - float64(q.B)
+			spread(x+1, y, er, eg, eb, 7.0/16)
+			spread(x-1, y+1, er, eg, eb, 3.0/16)
+			spread(x, y+1, er, eg, eb, 5.0/16)
+			spread(x+1, y+1, er, eg, eb, 1.0/16)
+		}
+	}
+}
+
+func clamp8(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,90 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/palette"
+)
+
+// Cell size in pixels at scale 1. The 8x8 glyphs are drawn with doubled
+// rows so cells keep the 1:2 proportions of a terminal character.
+const (
+	CellWidth  = 8
+	CellHeight = 16
+)
+
+var (
+	DefaultBackground = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	DefaultForeground = color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+)
+
+// Options controls how a frame is rasterised.
+type Options struct {
+	// Scale is an integer magnification factor; 0 means 1.
+	Scale int
+
+	// Background and Foreground are used for cells without their own
+	// colours. Nil selects DefaultBackground and DefaultForeground.
+	Background color.Color
+	Foreground color.Color
+
+	// Color enables the frame's per-cell colours.
+	Color bool
+}
+
+// Render draws a frame into a new image using the embedded bitmap font.
+func Render(f *frame.Frame, opts Options) *image.RGBA {
+	scale := opts.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	cellW := CellWidth * scale
+	cellH := CellHeight * scale
+	pixelH := cellH / 8 // height of one glyph row
+
+	bg, fg := DefaultBackground, DefaultForeground
+	if opts.Background != nil {
+		bg = palette.ToRGBA(opts.Background)
+	}
+	if opts.Foreground != nil {
+		fg = palette.ToRGBA(opts.Foreground)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, f.Width*cellW, f.Height*cellH))
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			cellBg, cellFg := bg, fg
+			if !f.IsTransparent(x, y) && opts.Color {
+				if c := f.GetColor(x, y); c != nil {
+					cellFg = palette.ToRGBA(c)
+				}
+				if c := f.GetBackground(x, y); c != nil {
+					cellBg = palette.ToRGBA(c)
+				}
+			}
+
+			ch := f.Get(x, y)
+			if ch == 0 {
+				ch = ' '
+			}
+			g := glyph(ch)
+
+			x0, y0 := x*cellW, y*cellH
+			for py := 0; py < cellH; py++ {
+				bits := g[py/pixelH]
+				row := img.Pix[img.PixOffset(x0, y0+py):]
+				for px := 0; px < cellW; px++ {
+					c := cellBg
+					if bits&(1<<(px/scale)) != 0 {
+						c = cellFg
+					}
+					i := px * 4
+					row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, c.A
+				}
+			}
+		}
+	}
+	return img
+}
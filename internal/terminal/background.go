@@ -0,0 +1,81 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/luminance"
+)
+
+// DefaultQueryTimeout is how long to wait for the terminal to answer a query.
+const DefaultQueryTimeout = 200 * time.Millisecond
+
+// QueryBackground asks the terminal for its background colour using OSC 11.
+// A primary device attributes request (DA1) is sent right after it; every
+// terminal answers DA1, so a DA1 reply without an OSC 11 reply means the
+// query is unsupported and we do not have to wait for the full timeout.
+func QueryBackground(timeout time.Duration) (color.RGBA, error) {
+	tty, err := OpenTTY()
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	defer tty.Close()
+
+	if err := tty.MakeRaw(); err != nil {
+		return color.RGBA{}, err
+	}
+
+	reply, err := tty.Query("\x1b]11;?\x1b\\\x1b[c", hasDA1Reply, timeout)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return parseOSC11(reply)
+}
+
+// IsLight reports whether a background colour is light.
+func IsLight(c color.Color) bool {
+	return luminance.Luminance(c) > 0.5
+}
+
+// hasDA1Reply reports whether the buffer contains a complete DA1 reply
+// ("ESC [ ? ... c").
+func hasDA1Reply(reply []byte) bool {
+	i := bytes.Index(reply, []byte("\x1b[?"))
+	return i >= 0 && bytes.IndexByte(reply[i:], 'c') >= 0
+}
+
+// parseOSC11 extracts the colour from a reply of the form
+// "ESC ] 11 ; rgb:RRRR/GGGG/BBBB" terminated by BEL or ST.
+func parseOSC11(reply []byte) (color.RGBA, error) {
+	start := bytes.Index(reply, []byte("]11;rgb:"))
+	if start < 0 {
+		return color.RGBA{}, fmt.Errorf("terminal does not report its background color")
+	}
+	body := reply[start+len("]11;rgb:"):]
+	if end := bytes.IndexAny(body, "\x07\x1b"); end >= 0 {
+		body = body[:end]
+	}
+
+	parts := bytes.Split(body, []byte("/"))
+	if len(parts) != 3 {
+		return color.RGBA{}, fmt.Errorf("malformed background color reply %q", body)
+	}
+
+	var rgb [3]uint8
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 4 {
+			return color.RGBA{}, fmt.Errorf("malformed background color reply %q", body)
+		}
+		v, err := strconv.ParseUint(string(part), 16, 16)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("malformed background color reply %q", body)
+		}
+		// Components are scaled to 1-4 hex digits; normalize to 8 bits.
+		max := uint64(1)<<(4*len(part)) - 1
+		rgb[i] = uint8(v * 255 / max)
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, nil
+}
@@ -0,0 +1,74 @@
+package terminal
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseOSC11(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"BEL", "\x1b]11;rgb:ffff/ffff/ffff\x07", color.RGBA{0xff, 0xff, 0xff, 0xff}, false},
+		{"ST", "\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\", color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, false},
+		{"followed by DA1", "\x1b]11;rgb:0000/8080/ffff\x1b\\\x1b[?62;22c", color.RGBA{0x00, 0x80, 0xff, 0xff}, false},
+		{"two digits", "\x1b]11;rgb:ff/80/00\x07", color.RGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"one digit", "\x1b]11;rgb:f/8/0\x07", color.RGBA{0xff, 0x88, 0x00, 0xff}, false},
+		{"only DA1", "\x1b[?62;22c", color.RGBA{}, true},
+		{"two components", "\x1b]11;rgb:ffff/ffff\x07", color.RGBA{}, true},
+		{"too many digits", "\x1b]11;rgb:fffff/0/0\x07", color.RGBA{}, true},
+		{"not hex", "\x1b]11;rgb:zz/00/00\x07", color.RGBA{}, true},
+		{"empty component", "\x1b]11;rgb:/00/00\x07", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOSC11([]byte(tt.reply))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOSC11() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseOSC11() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasDA1Reply(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  bool
+	}{
+		{"\x1b[?62;22c", true},
+		{"\x1b]11;rgb:0/0/0\x07\x1b[?1;2c", true},
+		{"\x1b[?62;22", false},
+		{"\x1b]11;rgb:0/0/0\x07", false},
+	}
+	for _, tt := range tests {
+		if got := hasDA1Reply([]byte(tt.reply)); got != tt.want {
+			t.Errorf("hasDA1Reply(%q) = %v, want %v", tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestParseBackgroundColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    BackgroundColor
+		wantErr bool
+	}{
+		{"none", BgNone, false},
+		{"black", BgBlack, false},
+		{"White", BgWhite, false},
+		{"grey", BgNone, true},
+		{"", BgNone, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBackgroundColor(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseBackgroundColor(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// QueryCellSize asks the terminal for the size of a character cell in
+// pixels using CSI 16 t. Like QueryBackground it follows the request with
+// DA1 so terminals that ignore it are detected without a full timeout.
+func QueryCellSize(timeout time.Duration) (width, height int, err error) {
+	tty, err := OpenTTY()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tty.Close()
+
+	if err := tty.MakeRaw(); err != nil {
+		return 0, 0, err
+	}
+
+	reply, err := tty.Query("\x1b[16t\x1b[c", hasDA1Reply, timeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseCellSize(reply)
+}
+
+// DetectCellAspect returns the width/height ratio of a character cell. The
+// pixel dimensions reported with the window size are used when present,
+// otherwise the terminal is queried. It returns zero when neither works,
+// leaving the caller to fall back to its default.
+func DetectCellAspect(size Size, timeout time.Duration) float64 {
+	if aspect := size.CellAspect(); aspect > 0 {
+		return aspect
+	}
+	width, height, err := QueryCellSize(timeout)
+	if err != nil {
+		return 0
+	}
+	return float64(width) / float64(height)
+}
+
+// parseCellSize extracts the cell size from a reply of the form
+// "ESC [ 6 ; height ; width t".
+func parseCellSize(reply []byte) (width, height int, err error) {
+	start := bytes.Index(reply, []byte("\x1b[6;"))
+	if start < 0 {
+		return 0, 0, fmt.Errorf("terminal does not report its cell size")
+	}
+	body := reply[start+len("\x1b[6;"):]
+	end := bytes.IndexByte(body, 't')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("malformed cell size reply %q", body)
+	}
+
+	parts := bytes.Split(body[:end], []byte(";"))
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("malformed cell size reply %q", body[:end])
+	}
+	height, err = strconv.Atoi(string(parts[0]))
+	if err != nil || height <= 0 {
+		return 0, 0, fmt.Errorf("malformed cell size reply %q", body[:end])
+	}
+	width, err = strconv.Atoi(string(parts[1]))
+	if err != nil || width <= 0 {
+		return 0, 0, fmt.Errorf("malformed cell size reply %q", body[:end])
+	}
+	return width, height, nil
+}
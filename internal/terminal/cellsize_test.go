@@ -0,0 +1,57 @@
+package terminal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseCellSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		reply         string
+		width, height int
+		wantErr       bool
+	}{
+		{"reply", "\x1b[6;20;10t", 10, 20, false},
+		{"followed by DA1", "\x1b[6;17;8t\x1b[?62;22c", 8, 17, false},
+		{"after other output", "junk\x1b[6;32;16t", 16, 32, false},
+		{"only DA1", "\x1b[?62;22c", 0, 0, true},
+		{"unterminated", "\x1b[6;20;10", 0, 0, true},
+		{"one value", "\x1b[6;20t", 0, 0, true},
+		{"three values", "\x1b[6;20;10;5t", 0, 0, true},
+		{"zero", "\x1b[6;0;10t", 0, 0, true},
+		{"not a number", "\x1b[6;x;10t", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, err := parseCellSize([]byte(tt.reply))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCellSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if width != tt.width || height != tt.height {
+				t.Errorf("parseCellSize() = %dx%d, want %dx%d", width, height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestCellAspect(t *testing.T) {
+	tests := []struct {
+		size Size
+		want float64
+	}{
+		{Size{Width: 80, Height: 24, PixelWidth: 800, PixelHeight: 480}, 0.5},
+		{Size{Width: 100, Height: 50, PixelWidth: 900, PixelHeight: 900}, 0.5},
+		{Size{Width: 80, Height: 40, PixelWidth: 640, PixelHeight: 640}, 0.5},
+		{Size{Width: 80, Height: 24, PixelWidth: 640, PixelHeight: 384}, 0.5},
+		{Size{Width: 10, Height: 10, PixelWidth: 70, PixelHeight: 100}, 0.7},
+		{Size{Width: 80, Height: 24}, 0},
+		{Size{Width: 80, Height: 24, PixelWidth: 800}, 0},
+		{Size{PixelWidth: 800, PixelHeight: 480}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.size.CellAspect(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%+v.CellAspect() = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
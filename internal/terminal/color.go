@@ -0,0 +1,213 @@
+package terminal
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+
+	"github.com/kozmaoliver/asciify/internal/palette"
+)
+
+// ColorDepth is the number of colours a terminal can display.
+type ColorDepth int
+
+const (
+	DepthMono ColorDepth = iota
+	Depth8
+	Depth16
+	Depth256
+	DepthTrueColor
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case DepthMono:
+		return "mono"
+	case Depth8:
+		return "8"
+	case Depth16:
+		return "16"
+	case Depth256:
+		return "256"
+	case DepthTrueColor:
+		return "truecolor"
+	}
+	return fmt.Sprintf("ColorDepth(%d)", int(d))
+}
+
+// ParseColorDepth parses a -colors flag value. "auto" detects the depth
+// from the environment.
+func ParseColorDepth(s string) (ColorDepth, error) {
+	switch strings.ToLower(s) {
+	case "auto", "":
+		return DetectColorDepth(), nil
+	case "truecolor", "24bit", "16m":
+		return DepthTrueColor, nil
+	case "256":
+		return Depth256, nil
+	case "16":
+		return Depth16, nil
+	case "8":
+		return Depth8, nil
+	case "mono", "none", "2":
+		return DepthMono, nil
+	}
+	return DepthMono, fmt.Errorf("unknown color depth %q (want auto, truecolor, 256, 16, 8 or mono)", s)
+}
+
+// DetectColorDepth guesses the colour depth of the terminal from NO_COLOR,
+// COLORTERM, TERM and the terminfo database.
+func DetectColorDepth() ColorDepth {
+	// https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		return DepthMono
+	}
+
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return DepthTrueColor
+	}
+
+	term := strings.ToLower(os.Getenv("TERM"))
+	if term == "dumb" {
+		return DepthMono
+	}
+	if strings.Contains(term, "truecolor") || strings.Contains(term, "24bit") || strings.HasSuffix(term, "-direct") {
+		return DepthTrueColor
+	}
+
+	if colors, ok := terminfoColors(term); ok {
+		switch {
+		case colors >= 1<<24:
+			return DepthTrueColor
+		case colors >= 256:
+			return Depth256
+		case colors >= 16:
+			return Depth16
+		case colors >= 8:
+			return Depth8
+		default:
+			return DepthMono
+		}
+	}
+
+	switch {
+	case strings.Contains(term, "256color"):
+		return Depth256
+	case term == "linux" || strings.HasPrefix(term, "vt"):
+		return Depth8
+	}
+	return Depth16
+}
+
+// ansiPalette holds the xterm default colours for the 16 ANSI colours
+// followed by the 240 colours of the xterm 256-colour cube and gray ramp.
+var ansiPalette = newANSIPalette()
+
+func newANSIPalette() *palette.Palette {
+	colors := []color.RGBA{
+		{0x00, 0x00, 0x00, 0xff}, // black
+		{0xcd, 0x00, 0x00, 0xff}, // red
+		{0x00, 0xcd, 0x00, 0xff}, // green
+		{0xcd, 0xcd, 0x00, 0xff}, // yellow
+		{0x00, 0x00, 0xee, 0xff}, // blue
+		{0xcd, 0x00, 0xcd, 0xff}, // magenta
+		{0x00, 0xcd, 0xcd, 0xff}, // cyan
+		{0xe5, 0xe5, 0xe5, 0xff}, // white
+		{0x7f, 0x7f, 0x7f, 0xff}, // bright black
+		{0xff, 0x00, 0x00, 0xff}, // bright red
+		{0x00, 0xff, 0x00, 0xff}, // bright green
+		{0xff, 0xff, 0x00, 0xff}, // bright yellow
+		{0x5c, 0x5c, 0xff, 0xff}, // bright blue
+		{0xff, 0x00, 0xff, 0xff}, // bright magenta
+		{0x00, 0xff, 0xff, 0xff}, // bright cyan
+		{0xff, 0xff, 0xff, 0xff}, // bright white
+	}
+
+	levels := []uint8{0, 95, 135, 175, 215, 255}
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for b := 0; b < 6; b++ {
+				colors = append(colors, color.RGBA{levels[r], levels[g], levels[b], 0xff})
+			}
+		}
+	}
+	for i := 0; i < 24; i++ {
+		v := uint8(8 + 10*i)
+		colors = append(colors, color.RGBA{v, v, v, 0xff})
+	}
+
+	return palette.New("xterm-256", colors)
+}
+
+// ANSIPalette returns the xterm 256-colour palette. Indices 0-15 are the
+// standard and bright ANSI colours.
+func ANSIPalette() *palette.Palette {
+	return ansiPalette
+}
+
+// colorEncoder turns colours into SGR escape sequences for a given depth,
+// caching the sequence for every distinct colour it has seen.
+type colorEncoder struct {
+	depth   ColorDepth
+	fgCache map[uint32]string
+	bgCache map[uint32]string
+}
+
+func newColorEncoder(depth ColorDepth) *colorEncoder {
+	return &colorEncoder{
+		depth:   depth,
+		fgCache: make(map[uint32]string),
+		bgCache: make(map[uint32]string),
+	}
+}
+
+// foreground returns the escape sequence selecting c as foreground colour,
+// or "" when the depth has no colours.
+func (e *colorEncoder) foreground(c color.Color) string {
+	return e.encode(c, e.fgCache, 38, 30, 90)
+}
+
+// background returns the escape sequence selecting c as background colour,
+// or "" when the depth has no colours.
+func (e *colorEncoder) background(c color.Color) string {
+	return e.encode(c, e.bgCache, 48, 40, 100)
+}
+
+// encode builds an SGR sequence. extended is 38 or 48, the prefix of
+// 256-colour and truecolor sequences; base and bright are the first codes
+// of the 8 standard and 8 bright colours.
+func (e *colorEncoder) encode(c color.Color, cache map[uint32]string, extended, base, bright int) string {
+	if e.depth == DepthMono {
+		return ""
+	}
+
+	r, g, b, _ := c.RGBA()
+	r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
+	key := uint32(r8)<<16 | uint32(g8)<<8 | uint32(b8)
+	if code, ok := cache[key]; ok {
+		return code
+	}
+
+	rgb := color.RGBA{r8, g8, b8, 0xff}
+	var code string
+	switch e.depth {
+	case DepthTrueColor:
+		code = fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", extended, r8, g8, b8)
+	case Depth256:
+		code = fmt.Sprintf("\x1b[%d;5;%dm", extended, ansiPalette.IndexIn(rgb, 16, 256))
+	case Depth16:
+		idx := ansiPalette.IndexIn(rgb, 0, 16)
+		if idx < 8 {
+			code = fmt.Sprintf("\x1b[%dm", base+idx)
+		} else {
+			code = fmt.Sprintf("\x1b[%dm", bright+idx-8)
+		}
+	case Depth8:
+		code = fmt.Sprintf("\x1b[%dm", base+ansiPalette.IndexIn(rgb, 0, 8))
+	}
+
+	cache[key] = code
+	return code
+}
@@ -0,0 +1,111 @@
+package terminal
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Key is a key press: a printable rune, a control character, or one of
+// the special keys below.
+type Key rune
+
+// Special keys, outside the Unicode range.
+const (
+	KeyUnknown Key = -(iota + 1)
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+)
+
+// KeyCtrlC is the key Ctrl+C sends in raw mode.
+const KeyCtrlC Key = 0x03
+
+// ReadKey waits for the next key press. The terminal should be in raw
+// mode; input that arrives together, such as fast typing or an escape
+// sequence, is split into individual keys across calls.
+func (t *TTY) ReadKey() (Key, error) {
+	return t.readKey(t.Read)
+}
+
+// ReadKeyTimeout is like ReadKey but gives up with ErrTimeout when no key
+// arrives within timeout.
+func (t *TTY) ReadKeyTimeout(timeout time.Duration) (Key, error) {
+	return t.readKey(func(p []byte) (int, error) {
+		return t.ReadTimeout(p, timeout)
+	})
+}
+
+func (t *TTY) readKey(read func(p []byte) (int, error)) (Key, error) {
+	if len(t.pending) == 0 {
+		buf := make([]byte, 64)
+		n, err := read(buf)
+		if err != nil {
+			return KeyUnknown, err
+		}
+		t.pending = buf[:n]
+	}
+
+	key, n := parseKey(t.pending)
+	t.pending = t.pending[n:]
+	return key, nil
+}
+
+// parseKey decodes the first key in b and returns it with the number of
+// bytes it used.
+func parseKey(b []byte) (Key, int) {
+	if b[0] != 0x1b {
+		r, n := utf8.DecodeRune(b)
+		return Key(r), n
+	}
+	if len(b) == 1 || (b[1] != '[' && b[1] != 'O') {
+		return KeyEscape, 1
+	}
+
+	// CSI or SS3: parameters, then a final byte in @-~.
+	end := 2
+	for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+		end++
+	}
+	if end == len(b) {
+		return KeyUnknown, len(b)
+	}
+
+	params := string(b[2:end])
+	var key Key
+	switch b[end] {
+	case 'A':
+		key = KeyUp
+	case 'B':
+		key = KeyDown
+	case 'C':
+		key = KeyRight
+	case 'D':
+		key = KeyLeft
+	case 'H':
+		key = KeyHome
+	case 'F':
+		key = KeyEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			key = KeyHome
+		case "4", "8":
+			key = KeyEnd
+		case "5":
+			key = KeyPageUp
+		case "6":
+			key = KeyPageDown
+		default:
+			key = KeyUnknown
+		}
+	default:
+		key = KeyUnknown
+	}
+	return key, end + 1
+}
@@ -1,93 +1,213 @@
 package terminal
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/kozmaoliver/asciify/internal/frame"
-	"image/color"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type BackgroundColor string
 
 const (
-	BgNone   BackgroundColor = "none"
-	BgBlack  BackgroundColor = "black"
-	BgWhite  BackgroundColor = "white"
+	BgNone  BackgroundColor = "none"
+	BgBlack BackgroundColor = "black"
+	BgWhite BackgroundColor = "white"
 )
 
+// ParseBackgroundColor parses a -bg flag value.
+func ParseBackgroundColor(s string) (BackgroundColor, error) {
+	switch bg := BackgroundColor(strings.ToLower(s)); bg {
+	case BgNone, BgBlack, BgWhite:
+		return bg, nil
+	}
+	return BgNone, fmt.Errorf("unknown background color %q (want none, black or white)", s)
+}
+
 // RenderFrame clears the screen and renders a frame to the terminal.
-func RenderFrame(f *frame.Frame, bgColor BackgroundColor, useColor bool) {
-	// Clear screen: move cursor to home position and clear entire screen
-	fmt.Print("\x1b[H\x1b[2J")
+// Colours are reduced to what the given colour depth can display.
+// When stdout is not a terminal the screen is not cleared, so piped
+// output stays clean.
+func RenderFrame(f *frame.Frame, bgColor BackgroundColor, useColor bool, depth ColorDepth) error {
+	r := NewRenderer(os.Stdout, bgColor, useColor, depth)
+	if IsTerminal(os.Stdout) {
+		// Clear screen: move cursor to home position and clear entire screen
+		r.w.WriteString("\x1b[H\x1b[2J")
+	}
+	return r.Render(f)
+}
+
+// WriteFrame writes a frame with ANSI escape sequences to w.
+func WriteFrame(w io.Writer, f *frame.Frame, bgColor BackgroundColor, useColor bool, depth ColorDepth) error {
+	return NewRenderer(w, bgColor, useColor, depth).Render(f)
+}
+
+// cell is a frame cell as drawn: the character and the escape sequences
+// of its colours.
+type cell struct {
+	ch     rune
+	fg, bg string
+}
+
+// blank is a cell of a cleared screen.
+var blank = cell{ch: ' '}
+
+// Renderer draws frames with ANSI escape sequences. Output is buffered
+// and written once per frame. Render streams a whole frame line by line;
+// Update draws at absolute positions and only emits the cells that
+// changed since the previous Update, which keeps animation smooth over
+// slow connections.
+type Renderer struct {
+	w        *bufio.Writer
+	bgCode   string
+	useColor bool
+	encoder  *colorEncoder
+
+	// Style currently set on the terminal.
+	curFg, curBg string
 
+	// Screen contents after the last Update; nil forces a full redraw.
+	prev          []cell
+	width, height int
+}
+
+// NewRenderer returns a renderer writing to w.
+func NewRenderer(w io.Writer, bgColor BackgroundColor, useColor bool, depth ColorDepth) *Renderer {
 	var bgCode string
 	switch bgColor {
 	case BgBlack:
 		bgCode = "\x1b[40m"
 	case BgWhite:
 		bgCode = "\x1b[47m"
-	case BgNone:
-		fallthrough
-	default:
-		bgCode = ""
 	}
-	if bgCode != "" {
-		fmt.Print(bgCode)
+	return &Renderer{
+		w:        bufio.NewWriterSize(w, 64*1024),
+		bgCode:   bgCode,
+		useColor: useColor,
+		encoder:  newColorEncoder(depth),
 	}
+}
 
-	lastColorCode := ""
+// Render writes the whole frame from the current cursor position,
+// followed by a line break.
+func (r *Renderer) Render(f *frame.Frame) error {
 	for y := 0; y < f.Height; y++ {
 		for x := 0; x < f.Width; x++ {
-			ch := f.Get(x, y)
-			if ch == 0 {
-				ch = ' '
-			}
+			r.put(r.cell(f, x, y))
+		}
 
-			var currentColorCode string
-			if useColor && f.Colors != nil {
-				c := f.GetColor(x, y)
-				if c != nil {
-					currentColorCode = colorToANSI(c)
-					if currentColorCode != lastColorCode {
-						if currentColorCode != "" {
-							if bgCode != "" {
-								fmt.Print(bgCode + currentColorCode)
-							} else {
-								fmt.Print(currentColorCode)
-							}
-							lastColorCode = currentColorCode
-						}
-					}
-				}
-			}
+		// Reset before the line break so backgrounds do not bleed into
+		// the rest of the line.
+		r.reset()
 
-			fmt.Fprintf(os.Stdout, "%c", ch)
+		if y < f.Height-1 {
+			r.w.WriteByte('\n')
 		}
+	}
+	r.w.WriteString("\n\x1b[0m")
+	return r.w.Flush()
+}
 
-		if useColor && lastColorCode != "" {
-			if bgCode != "" {
-				fmt.Print("\x1b[0m" + bgCode)
-			} else {
-				fmt.Print("\x1b[0m")
-			}
-			lastColorCode = ""
+// Update draws the frame at the top left of the screen. The first frame,
+// and any frame whose size differs from the previous one, clears the
+// screen; after that only changed cells are written.
+func (r *Renderer) Update(f *frame.Frame) error {
+	if r.prev == nil || f.Width != r.width || f.Height != r.height {
+		r.w.WriteString("\x1b[0m\x1b[H\x1b[2J")
+		r.curFg, r.curBg = "", ""
+		r.width, r.height = f.Width, f.Height
+		r.prev = make([]cell, f.Width*f.Height)
+		for i := range r.prev {
+			r.prev[i] = blank
 		}
+	}
 
-		if y < f.Height-1 {
-			fmt.Print("\n")
+	// Position of the cursor, or -1 when unknown.
+	cx, cy := -1, -1
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			c := r.cell(f, x, y)
+			i := y*f.Width + x
+			if c == r.prev[i] {
+				continue
+			}
+			r.prev[i] = c
+
+			if x != cx || y != cy {
+				r.moveTo(x, y)
+			}
+			r.put(c)
+			cx, cy = x+1, y
 		}
 	}
-	fmt.Print("\n")
+	r.reset()
+	return r.w.Flush()
+}
 
-	fmt.Print("\x1b[0m")
+// Invalidate forgets the screen contents, so the next Update redraws
+// everything. Call it when something else has written to the screen.
+func (r *Renderer) Invalidate() {
+	r.prev = nil
 }
 
-func colorToANSI(c color.Color) string {
-	r, g, b, _ := c.RGBA()
+// cell returns the character and colour sequences of a frame cell.
+func (r *Renderer) cell(f *frame.Frame, x, y int) cell {
+	// Transparent cells show the terminal's own background.
+	if f.IsTransparent(x, y) {
+		return blank
+	}
+
+	ch := f.Get(x, y)
+	if ch == 0 {
+		ch = ' '
+	}
 
-	r8 := r >> 8
-	g8 := g >> 8
-	b8 := b >> 8
+	c := cell{ch: ch, bg: r.bgCode}
+	if r.useColor {
+		if fg := f.GetColor(x, y); fg != nil {
+			c.fg = r.encoder.foreground(fg)
+		}
+		if bg := f.GetBackground(x, y); bg != nil {
+			if code := r.encoder.background(bg); code != "" {
+				c.bg = code
+			}
+		}
+	}
+	return c
+}
+
+// put writes a cell, changing the terminal's style only where needed.
+func (r *Renderer) put(c cell) {
+	// Dropping a colour needs a reset; changing one does not.
+	if (r.curFg != "" && c.fg == "") || (r.curBg != "" && c.bg == "") {
+		r.reset()
+	}
+	if c.bg != r.curBg {
+		r.w.WriteString(c.bg)
+		r.curBg = c.bg
+	}
+	if c.fg != r.curFg {
+		r.w.WriteString(c.fg)
+		r.curFg = c.fg
+	}
+	r.w.WriteRune(c.ch)
+}
+
+func (r *Renderer) reset() {
+	if r.curFg != "" || r.curBg != "" {
+		r.w.WriteString("\x1b[0m")
+		r.curFg, r.curBg = "", ""
+	}
+}
 
-	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r8, g8, b8)
+// moveTo positions the cursor at the zero-based cell x, y.
+func (r *Renderer) moveTo(x, y int) {
+	r.w.WriteString("\x1b[")
+	r.w.WriteString(strconv.Itoa(y + 1))
+	r.w.WriteByte(';')
+	r.w.WriteString(strconv.Itoa(x + 1))
+	r.w.WriteByte('H')
 }
@@ -0,0 +1,137 @@
+package terminal
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/kozmaoliver/asciify/internal/frame"
+)
+
+func textFrame(lines ...string) *frame.Frame {
+	f := frame.New(len([]rune(lines[0])), len(lines))
+	for y, line := range lines {
+		for x, ch := range []rune(line) {
+			f.Set(x, y, ch)
+		}
+	}
+	return f
+}
+
+func TestRendererUpdate(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+
+	tests := []struct {
+		name   string
+		prev   *frame.Frame
+		next   *frame.Frame
+		color  bool
+		bg     BackgroundColor
+		output string
+	}{
+		{
+			name:   "first frame clears",
+			next:   textFrame("ab", "cd"),
+			output: "\x1b[0m\x1b[H\x1b[2J\x1b[1;1Hab\x1b[2;1Hcd",
+		},
+		{
+			name:   "blank cells are skipped",
+			next:   textFrame("a b"),
+			output: "\x1b[0m\x1b[H\x1b[2J\x1b[1;1Ha\x1b[1;3Hb",
+		},
+		{
+			name:   "unchanged frame",
+			prev:   textFrame("ab", "cd"),
+			next:   textFrame("ab", "cd"),
+			output: "",
+		},
+		{
+			name:   "changed cells only",
+			prev:   textFrame("abcd", "efgh"),
+			next:   textFrame("aXYd", "efgZ"),
+			output: "\x1b[1;2HXY\x1b[2;4HZ",
+		},
+		{
+			name:   "resize clears",
+			prev:   textFrame("ab"),
+			next:   textFrame("abc"),
+			output: "\x1b[0m\x1b[H\x1b[2J\x1b[1;1Habc",
+		},
+		{
+			name: "colour change",
+			prev: textFrame("ab"),
+			next: func() *frame.Frame {
+				f := textFrame("ab")
+				f.EnableColors()
+				f.SetColor(1, 0, red)
+				return f
+			}(),
+			color:  true,
+			output: "\x1b[1;2H\x1b[38;2;255;0;0mb\x1b[0m",
+		},
+		{
+			name: "transparent cell",
+			prev: textFrame("ab"),
+			next: func() *frame.Frame {
+				f := textFrame("ab")
+				f.SetTransparent(0, 0)
+				return f
+			}(),
+			bg:     BgBlack,
+			output: "\x1b[1;1H ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bg := tt.bg
+			if bg == "" {
+				bg = BgNone
+			}
+			var buf bytes.Buffer
+			r := NewRenderer(&buf, bg, tt.color, DepthTrueColor)
+			if tt.prev != nil {
+				if err := r.Update(tt.prev); err != nil {
+					t.Fatal(err)
+				}
+				buf.Reset()
+			}
+			if err := r.Update(tt.next); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.output {
+				t.Errorf("Update() wrote %q, want %q", got, tt.output)
+			}
+		})
+	}
+}
+
+func TestRendererInvalidate(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRenderer(&buf, BgNone, false, DepthMono)
+	f := textFrame("ab")
+	if err := r.Update(f); err != nil {
+		t.Fatal(err)
+	}
+	r.Invalidate()
+	buf.Reset()
+	if err := r.Update(f); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[0m\x1b[H\x1b[2J\x1b[1;1Hab"; buf.String() != want {
+		t.Errorf("Update() after Invalidate wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteFrameBackgrounds(t *testing.T) {
+	f := textFrame("ab")
+	f.EnableBackgrounds()
+	f.SetBackground(0, 0, color.RGBA{0, 0, 0xff, 0xff})
+
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, f, BgNone, true, Depth16); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[44ma\x1b[0mb\n\x1b[0m"; buf.String() != want {
+		t.Errorf("WriteFrame() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,54 @@
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultResizeDebounce is how long WatchResize waits for a burst of
+// resize signals to settle, so dragging a window or tmux pane border
+// re-renders once rather than on every step.
+const DefaultResizeDebounce = 100 * time.Millisecond
+
+// WatchResize subscribes to SIGWINCH and sends the new terminal size on
+// the returned channel once no further resize has arrived for debounce.
+// Only the latest size is kept if the receiver falls behind. Call stop to
+// unsubscribe.
+func WatchResize(debounce time.Duration) (sizes <-chan Size, stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	out := make(chan Size, 1)
+	done := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+		for {
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case <-signals:
+				timer.Reset(debounce)
+			case <-timer.C:
+				size, err := GetTerminalSize()
+				if err != nil {
+					continue
+				}
+				// Replace a size the receiver has not picked up yet.
+				select {
+				case <-out:
+				default:
+				}
+				out <- size
+			}
+		}
+	}()
+
+	return out, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
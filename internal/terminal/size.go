@@ -1,14 +1,28 @@
 package terminal
 
 import (
-	"os"
 	"golang.org/x/sys/unix"
+	"os"
 )
 
 // Size represents terminal dimensions in characters.
 type Size struct {
 	Width  int
 	Height int
+
+	// PixelWidth and PixelHeight are the dimensions of the text area in
+	// pixels, or zero when the terminal does not report them.
+	PixelWidth  int
+	PixelHeight int
+}
+
+// CellAspect returns the width/height ratio of a character cell, or zero
+// when the pixel dimensions are unknown.
+func (s Size) CellAspect() float64 {
+	if s.Width <= 0 || s.Height <= 0 || s.PixelWidth <= 0 || s.PixelHeight <= 0 {
+		return 0
+	}
+	return (float64(s.PixelWidth) / float64(s.Width)) / (float64(s.PixelHeight) / float64(s.Height))
 }
 
 func GetTerminalSize() (Size, error) {
@@ -18,7 +32,9 @@ func GetTerminalSize() (Size, error) {
 	}
 
 	return Size{
-		Width:  int(ws.Col),
-		Height: int(ws.Row),
+		Width:       int(ws.Col),
+		Height:      int(ws.Row),
+		PixelWidth:  int(ws.Xpixel),
+		PixelHeight: int(ws.Ypixel),
 	}, nil
 }
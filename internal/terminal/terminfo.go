@@ -0,0 +1,97 @@
+package terminal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Compiled terminfo magic numbers (see term(5)).
+const (
+	terminfoMagic    = 0432  // 16-bit numbers
+	terminfoMagic32  = 01036 // 32-bit numbers (ncurses 6.1+)
+	terminfoColorsIx = 13    // index of the "colors" numeric capability
+)
+
+// terminfoColors looks up the "colors" capability of the named terminal in
+// the terminfo database.
+func terminfoColors(term string) (int, bool) {
+	if term == "" {
+		return 0, false
+	}
+
+	for _, dir := range terminfoDirs() {
+		for _, sub := range []string{term[:1], fmt.Sprintf("%x", term[0])} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, term))
+			if err != nil {
+				continue
+			}
+			return parseTerminfoColors(data)
+		}
+	}
+	return 0, false
+}
+
+func terminfoDirs() []string {
+	var dirs []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	for _, dir := range strings.Split(os.Getenv("TERMINFO_DIRS"), ":") {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo")
+}
+
+func parseTerminfoColors(data []byte) (int, bool) {
+	if len(data) < 12 {
+		return 0, false
+	}
+
+	header := make([]int, 6)
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(data[i*2:])))
+	}
+
+	numSize := 2
+	switch header[0] {
+	case terminfoMagic:
+	case terminfoMagic32:
+		numSize = 4
+	default:
+		return 0, false
+	}
+
+	namesSize, boolCount, numCount := header[1], header[2], header[3]
+	if numCount <= terminfoColorsIx {
+		return 0, false
+	}
+
+	offset := 12 + namesSize + boolCount
+	// Numbers are aligned to an even byte boundary.
+	if offset%2 != 0 {
+		offset++
+	}
+	offset += terminfoColorsIx * numSize
+	if offset+numSize > len(data) {
+		return 0, false
+	}
+
+	var colors int
+	if numSize == 4 {
+		colors = int(int32(binary.LittleEndian.Uint32(data[offset:])))
+	} else {
+		colors = int(int16(binary.LittleEndian.Uint16(data[offset:])))
+	}
+	if colors < 0 {
+		return 0, false
+	}
+	return colors, true
+}
@@ -0,0 +1,105 @@
+package terminal
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// compileTerminfo builds a minimal compiled terminfo entry whose numeric
+// capabilities are all absent except "colors".
+func compileTerminfo(magic int, names string, boolCount, colors int) []byte {
+	numSize := 2
+	if magic == terminfoMagic32 {
+		numSize = 4
+	}
+	numCount := terminfoColorsIx + 1
+
+	var data []byte
+	for _, v := range []int{magic, len(names) + 1, boolCount, numCount, 0, 0} {
+		data = binary.LittleEndian.AppendUint16(data, uint16(v))
+	}
+	data = append(data, names...)
+	data = append(data, 0)
+	data = append(data, make([]byte, boolCount)...)
+	if len(data)%2 != 0 {
+		data = append(data, 0)
+	}
+	for i := 0; i < numCount; i++ {
+		v := -1
+		if i == terminfoColorsIx {
+			v = colors
+		}
+		if numSize == 4 {
+			data = binary.LittleEndian.AppendUint32(data, uint32(int32(v)))
+		} else {
+			data = binary.LittleEndian.AppendUint16(data, uint16(int16(v)))
+		}
+	}
+	return data
+}
+
+func TestParseTerminfoColors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		colors int
+		ok     bool
+	}{
+		{"16-bit", compileTerminfo(terminfoMagic, "xterm-256color|xterm", 38, 256), 256, true},
+		{"odd names and bools", compileTerminfo(terminfoMagic, "vt100", 4, 8), 8, true},
+		{"32-bit", compileTerminfo(terminfoMagic32, "xterm-direct", 38, 1<<24), 1 << 24, true},
+		{"absent", compileTerminfo(terminfoMagic, "dumb", 2, -1), 0, false},
+		{"bad magic", compileTerminfo(0x1234, "x", 0, 8), 0, false},
+		{"short", []byte{0x1a, 0x01}, 0, false},
+		{"truncated", compileTerminfo(terminfoMagic, "xterm", 38, 256)[:40], 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colors, ok := parseTerminfoColors(tt.data)
+			if colors != tt.colors || ok != tt.ok {
+				t.Errorf("parseTerminfoColors() = %d, %v; want %d, %v", colors, ok, tt.colors, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "a", "asciify-test-88")
+	if err := os.MkdirAll(filepath.Dir(entry), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry, compileTerminfo(terminfoMagic, "asciify-test-88", 0, 88), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		noColor, colorTerm, term string
+		want                     ColorDepth
+	}{
+		{"1", "truecolor", "xterm-256color", DepthMono},
+		{"", "truecolor", "xterm", DepthTrueColor},
+		{"", "24bit", "", DepthTrueColor},
+		{"", "", "dumb", DepthMono},
+		{"", "", "asciify-test-direct", DepthTrueColor},
+		{"", "", "asciify-test-88", Depth16},
+		{"", "", "asciify-test-256color", Depth256},
+		{"", "", "vt52-asciify-test", Depth8},
+		{"", "", "asciify-test", Depth16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			t.Setenv("TERMINFO", dir)
+			t.Setenv("TERMINFO_DIRS", "")
+			t.Setenv("HOME", dir)
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("COLORTERM", tt.colorTerm)
+			t.Setenv("TERM", tt.term)
+			if got := DetectColorDepth(); got != tt.want {
+				t.Errorf("DetectColorDepth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
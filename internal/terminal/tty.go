@@ -0,0 +1,138 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrTimeout is returned when the terminal does not answer a query, or no
+// input arrives, in time.
+var ErrTimeout = errors.New("terminal did not respond in time")
+
+// TTY is the controlling terminal, opened independently of stdin/stdout so
+// it can be queried even when those are redirected.
+type TTY struct {
+	file  *os.File
+	state *unix.Termios
+
+	// Input read by ReadKey but not yet returned.
+	pending []byte
+}
+
+// IsTerminal reports whether f refers to a terminal.
+func IsTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlGetTermios)
+	return err == nil
+}
+
+// OpenTTY opens the controlling terminal for reading and writing.
+func OpenTTY() (*TTY, error) {
+	file, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &TTY{file: file}, nil
+}
+
+// MakeRaw puts the terminal into raw mode: no echo, no line buffering and
+// no signal or output processing. Restore undoes it.
+func (t *TTY) MakeRaw() error {
+	fd := int(t.file.Fd())
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return err
+	}
+
+	raw := *state
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return err
+	}
+	if t.state == nil {
+		t.state = state
+	}
+	return nil
+}
+
+// Restore returns the terminal to the mode it was in before MakeRaw.
+func (t *TTY) Restore() error {
+	if t.state == nil {
+		return nil
+	}
+	err := unix.IoctlSetTermios(int(t.file.Fd()), ioctlSetTermios, t.state)
+	t.state = nil
+	return err
+}
+
+func (t *TTY) Read(p []byte) (int, error) {
+	return t.file.Read(p)
+}
+
+func (t *TTY) Write(p []byte) (int, error) {
+	return t.file.Write(p)
+}
+
+// Fd returns the file descriptor of the terminal.
+func (t *TTY) Fd() uintptr {
+	return t.file.Fd()
+}
+
+// Close restores the terminal mode and closes it.
+func (t *TTY) Close() error {
+	t.Restore()
+	return t.file.Close()
+}
+
+// ReadTimeout reads whatever input is available, waiting at most timeout
+// for it to arrive.
+func (t *TTY) ReadTimeout(p []byte, timeout time.Duration) (int, error) {
+	fds := []unix.PollFd{{Fd: int32(t.file.Fd()), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, ErrTimeout
+		}
+		return t.file.Read(p)
+	}
+}
+
+// Query writes a control sequence to the terminal and collects the reply
+// until done reports it complete or the timeout expires. The terminal must
+// be in raw mode so the reply is neither echoed nor line buffered.
+func (t *TTY) Query(request string, done func(reply []byte) bool, timeout time.Duration) ([]byte, error) {
+	if _, err := t.file.WriteString(request); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	var reply []byte
+	buf := make([]byte, 256)
+	for !done(reply) {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return reply, ErrTimeout
+		}
+		n, err := t.ReadTimeout(buf, remaining)
+		if err != nil {
+			return reply, err
+		}
+		reply = append(reply, buf[:n]...)
+	}
+	return reply, nil
+}
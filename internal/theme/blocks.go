@@ -0,0 +1,26 @@
+package theme
+
+// BlocksTheme maps luminance to Unicode shade blocks, for a smoother,
+// pixel-like look.
+type BlocksTheme struct{}
+
+func NewBlocksTheme() *BlocksTheme {
+	return &BlocksTheme{}
+}
+
+func (t *BlocksTheme) Name() string {
+	return "blocks"
+}
+
+func (t *BlocksTheme) Characters() []rune {
+	return []rune{' ', '░', '▒', '▓', '█'}
+}
+
+func (t *BlocksTheme) BrightestChar() rune {
+	chars := t.Characters()
+	return chars[len(chars)-1]
+}
+
+func (t *BlocksTheme) EdgeChars() map[string]rune {
+	return NewDefaultTheme().EdgeChars()
+}
@@ -7,6 +7,10 @@ func NewDefaultTheme() *DefaultTheme {
 	return &DefaultTheme{}
 }
 
+func (t *DefaultTheme) Name() string {
+	return "default"
+}
+
 func (t *DefaultTheme) Characters() []rune {
 	return []rune{' ', '.', ':', '-', '=', '+', '*', '%', '@', '#'}
 }
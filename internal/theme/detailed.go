@@ -0,0 +1,26 @@
+package theme
+
+// DetailedTheme uses Paul Bourke's 70-level character ramp, which
+// resolves fine gradients at the cost of a noisier look.
+type DetailedTheme struct{}
+
+func NewDetailedTheme() *DetailedTheme {
+	return &DetailedTheme{}
+}
+
+func (t *DetailedTheme) Name() string {
+	return "detailed"
+}
+
+func (t *DetailedTheme) Characters() []rune {
+	return []rune(" .'`^\",:;Il!i><~+_-?][}{1)(|\\/tfjrxnuvczXYUJCLQ0OZmwqpdbkhao*#MW&8%B@$")
+}
+
+func (t *DetailedTheme) BrightestChar() rune {
+	chars := t.Characters()
+	return chars[len(chars)-1]
+}
+
+func (t *DetailedTheme) EdgeChars() map[string]rune {
+	return NewDefaultTheme().EdgeChars()
+}
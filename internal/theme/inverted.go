@@ -0,0 +1,34 @@
+package theme
+
+// InvertedTheme wraps a theme and reverses its luminance ramp, for
+// rendering dark glyphs on a light terminal background.
+type InvertedTheme struct {
+	Theme Theme
+}
+
+func NewInvertedTheme(t Theme) *InvertedTheme {
+	return &InvertedTheme{Theme: t}
+}
+
+func (t *InvertedTheme) Name() string {
+	return t.Theme.Name() + "-inverted"
+}
+
+func (t *InvertedTheme) Characters() []rune {
+	chars := t.Theme.Characters()
+	inverted := make([]rune, len(chars))
+	for i, ch := range chars {
+		inverted[len(chars)-1-i] = ch
+	}
+	return inverted
+}
+
+// BrightestChar returns the wrapped theme's brightest character, which is
+// still the densest glyph and so the best carrier for colour output.
+func (t *InvertedTheme) BrightestChar() rune {
+	return t.Theme.BrightestChar()
+}
+
+func (t *InvertedTheme) EdgeChars() map[string]rune {
+	return t.Theme.EdgeChars()
+}
@@ -0,0 +1,25 @@
+package theme
+
+// MinimalTheme is a sparse 5-level ramp that keeps the focus on edges.
+type MinimalTheme struct{}
+
+func NewMinimalTheme() *MinimalTheme {
+	return &MinimalTheme{}
+}
+
+func (t *MinimalTheme) Name() string {
+	return "minimal"
+}
+
+func (t *MinimalTheme) Characters() []rune {
+	return []rune{' ', '.', '-', '+', '#'}
+}
+
+func (t *MinimalTheme) BrightestChar() rune {
+	chars := t.Characters()
+	return chars[len(chars)-1]
+}
+
+func (t *MinimalTheme) EdgeChars() map[string]rune {
+	return NewDefaultTheme().EdgeChars()
+}
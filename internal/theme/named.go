@@ -0,0 +1,41 @@
+package theme
+
+import (
+	"fmt"
+	"strings"
+)
+
+// themes lists the built-in themes in cycling order.
+var themes = []func() Theme{
+	func() Theme { return NewDefaultTheme() },
+	func() Theme { return NewDetailedTheme() },
+	func() Theme { return NewMinimalTheme() },
+	func() Theme { return NewBlocksTheme() },
+}
+
+// Named returns the built-in theme with the given name.
+func Named(name string) (Theme, bool) {
+	for _, newTheme := range themes {
+		if t := newTheme(); t.Name() == strings.ToLower(name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup is like Named but returns an error listing the valid names.
+func Lookup(name string) (Theme, error) {
+	if t, ok := Named(name); ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Names returns the names of the built-in themes in cycling order.
+func Names() []string {
+	names := make([]string, len(themes))
+	for i, newTheme := range themes {
+		names[i] = newTheme().Name()
+	}
+	return names
+}
@@ -2,6 +2,9 @@ package theme
 
 // Theme defines the interface for ASCII character themes.
 type Theme interface {
+	// Name returns the name of the theme.
+	Name() string
+
 	// Characters returns the ordered list of characters for luminance mapping.
 	// Characters should be ordered from darkest to brightest.
 	Characters() []rune
@@ -0,0 +1,59 @@
+package viewer
+
+import (
+	"image"
+
+	"github.com/kozmaoliver/asciify/internal/imageio"
+)
+
+// slide is an image being decoded in the background. done is closed once
+// img or err is set.
+type slide struct {
+	path string
+	img  image.Image
+	err  error
+	done chan struct{}
+}
+
+// slides decodes the images of a slideshow on demand, keeping only the
+// current image and its neighbours in memory.
+type slides struct {
+	paths  []string
+	decode imageio.DecodeOptions
+	loaded map[int]*slide
+}
+
+func newSlides(paths []string, decode imageio.DecodeOptions) *slides {
+	return &slides{paths: paths, decode: decode, loaded: make(map[int]*slide)}
+}
+
+// get returns slide i, starting to decode it if needed.
+func (s *slides) get(i int) *slide {
+	if sl, ok := s.loaded[i]; ok {
+		return sl
+	}
+	sl := &slide{path: s.paths[i], done: make(chan struct{})}
+	go func() {
+		sl.img, sl.err = imageio.LoadImage(sl.path, s.decode)
+		close(sl.done)
+	}()
+	s.loaded[i] = sl
+	return sl
+}
+
+// show waits for slide i, then starts decoding the next one and drops
+// everything but i and its neighbours.
+func (s *slides) show(i int) *slide {
+	sl := s.get(i)
+	<-sl.done
+
+	next := (i + 1) % len(s.paths)
+	prev := (i + len(s.paths) - 1) % len(s.paths)
+	s.get(next)
+	for j := range s.loaded {
+		if j != i && j != next && j != prev {
+			delete(s.loaded, j)
+		}
+	}
+	return sl
+}
@@ -0,0 +1,463 @@
+package viewer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kozmaoliver/asciify/internal/converter"
+	"github.com/kozmaoliver/asciify/internal/debug"
+	"github.com/kozmaoliver/asciify/internal/frame"
+	"github.com/kozmaoliver/asciify/internal/imageio"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+	"github.com/kozmaoliver/asciify/internal/theme"
+)
+
+const (
+	maxZoom      = 32.0
+	zoomStep     = 1.25
+	panStep      = 0.1 // fraction of the visible area
+	cutoffStep   = 10.0
+	contrastStep = 0.1
+)
+
+// DefaultInterval is the slideshow interval when playback is started
+// with a key rather than Options.Interval.
+const DefaultInterval = 5 * time.Second
+
+// keyPollInterval is how often the key reader checks whether the viewer
+// has stopped.
+const keyPollInterval = 100 * time.Millisecond
+
+const helpText = " arrows/hjkl pan  +/- zoom  0 reset  n/p next/prev  s slideshow  t theme  c color  e edges  [ ] edge cutoff  < > contrast  q quit"
+
+var (
+	statusForeground = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	statusBackground = color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+)
+
+// Options configures the viewer.
+type Options struct {
+	// Decode configures how the images are decoded.
+	Decode imageio.DecodeOptions
+
+	// Convert holds the initial conversion settings; the viewer changes
+	// its theme, colour, edges, edge cutoff and contrast as keys are pressed.
+	Convert converter.Options
+
+	Background terminal.BackgroundColor
+	Depth      terminal.ColorDepth
+
+	// Interval, if positive, starts the slideshow playing, advancing to
+	// the next image at this interval.
+	Interval time.Duration
+}
+
+// Viewer shows images full screen and re-runs the conversion pipeline
+// as the user pans, zooms and tunes parameters. With several images it is
+// a slideshow; the next image is decoded while the current one is shown.
+type Viewer struct {
+	opts Options
+
+	slides  *slides
+	index   int
+	current *slide
+	img     image.Image // nil if the current image failed to decode
+	playing bool
+
+	convert    converter.Options
+	themeIndex int
+	invert     bool
+
+	// zoom is the magnification of the source image; 1 shows all of it.
+	// centerX and centerY are the centre of the visible area in source pixels.
+	zoom             float64
+	centerX, centerY float64
+
+	help     bool
+	renderer *terminal.Renderer
+}
+
+// New returns a viewer for the images at paths.
+func New(paths []string, opts Options) *Viewer {
+	v := &Viewer{
+		opts:     opts,
+		slides:   newSlides(paths, opts.Decode),
+		playing:  opts.Interval > 0,
+		convert:  opts.Convert,
+		renderer: terminal.NewRenderer(os.Stdout, opts.Background, opts.Depth != terminal.DepthMono, opts.Depth),
+	}
+	// Whole-image steps are applied once by show, not on every draw.
+	v.convert.Stages = nil
+	v.convert.Autocrop = imageio.AutocropOff
+	v.convert.ChromaKey = nil
+	v.convert.Matte = nil
+	v.convert.Fit = imageio.FitContain
+	if v.convert.Contrast <= 0 {
+		v.convert.Contrast = 1
+	}
+
+	// Themes are cycled by name; an inverted theme stays inverted.
+	base := v.convert.Theme
+	if inverted, ok := base.(*theme.InvertedTheme); ok {
+		base = inverted.Theme
+		v.invert = true
+	}
+	for i, name := range theme.Names() {
+		if name == base.Name() {
+			v.themeIndex = i
+		}
+	}
+
+	return v
+}
+
+// Run shows the viewer until the user quits or the process is told to
+// stop with SIGINT, SIGTERM or SIGHUP. It switches the terminal to the
+// alternate screen and raw mode, and restores both on return.
+func (v *Viewer) Run() error {
+	tty, err := terminal.OpenTTY()
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	if err := tty.MakeRaw(); err != nil {
+		return err
+	}
+
+	// Alternate screen, hidden cursor.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[0m\x1b[?25h\x1b[?1049l")
+
+	// Signals end the loop like q does, so the terminal is restored on
+	// the way out instead of being left in raw mode.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	// Keys are read in the background so signals and resizes can
+	// interrupt the wait.
+	type keyPress struct {
+		key terminal.Key
+		err error
+	}
+	// The reader polls so it notices done, and Run waits for it to stop
+	// before the tty is closed.
+	keys := make(chan keyPress, 1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			key, err := tty.ReadKeyTimeout(keyPollInterval)
+			if err == terminal.ErrTimeout {
+				select {
+				case <-done:
+					return
+				default:
+					continue
+				}
+			}
+			select {
+			case keys <- keyPress{key, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	resizes, stopResize := terminal.WatchResize(terminal.DefaultResizeDebounce)
+	defer stopResize()
+
+	var ticker *time.Ticker
+	var advance <-chan time.Time
+	interval := v.opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if len(v.slides.paths) > 1 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		advance = ticker.C
+	}
+
+	v.show(0)
+	for {
+		if err := v.draw(); err != nil {
+			return err
+		}
+
+		redraw := false
+		for !redraw {
+			select {
+			case sig := <-signals:
+				debug.Log("Viewer: received %v", sig)
+				return nil
+			case size := <-resizes:
+				debug.Log("Viewer: terminal resized to %dx%d", size.Width, size.Height)
+				if aspect := size.CellAspect(); aspect > 0 {
+					v.convert.CellAspect = aspect
+				}
+				// The terminal may have reflowed the old contents.
+				v.renderer.Invalidate()
+				// Autocrop depends on the terminal's shape, so the image
+				// is prepared again; the view is kept if it still fits.
+				previous := v.img
+				v.prepare()
+				if v.img != nil && (previous == nil || v.img.Bounds() != previous.Bounds()) {
+					v.resetView()
+				}
+				redraw = true
+			case <-advance:
+				if v.playing {
+					v.show(v.index + 1)
+					redraw = true
+				}
+			case press := <-keys:
+				if press.err != nil {
+					return press.err
+				}
+				key := press.key
+				if key == 'q' || key == 'Q' || key == terminal.KeyEscape || key == terminal.KeyCtrlC {
+					return nil
+				}
+				index, playing := v.index, v.playing
+				redraw = v.handle(key)
+				// A manual move, or starting the slideshow, gives the
+				// image shown now a full interval.
+				if ticker != nil && (v.index != index || v.playing != playing) {
+					ticker.Reset(interval)
+				}
+			}
+		}
+	}
+}
+
+// show makes image i, wrapping around, the current one, waiting for it
+// to finish decoding if necessary.
+func (v *Viewer) show(i int) {
+	n := len(v.slides.paths)
+	v.index = (i%n + n) % n
+	v.current = v.slides.show(v.index)
+	v.prepare()
+	if v.current.err != nil {
+		debug.Log("Viewer: %s: %v", v.current.path, v.current.err)
+	}
+	v.resetView()
+}
+
+// prepare applies the whole-image steps, such as transforms and autocrop,
+// to the current image for the terminal's size. They come before the
+// viewer's own zoom.
+func (v *Viewer) prepare() {
+	v.img = v.current.img
+	if v.img == nil {
+		return
+	}
+	opts := v.opts.Convert
+	opts.CellAspect = v.convert.CellAspect
+	size, _ := terminal.GetTerminalSize()
+	prepared, _ := converter.Prepare([]image.Image{v.img}, size.Width, max(1, size.Height-1), opts)
+	v.img = prepared[0]
+}
+
+// handle applies a key press and reports whether the screen needs redrawing.
+func (v *Viewer) handle(key terminal.Key) bool {
+	switch key {
+	case 'n', ' ', terminal.KeyPageDown:
+		v.show(v.index + 1)
+		return true
+	case 'p', 'b', 0x7f, terminal.KeyPageUp:
+		v.show(v.index - 1)
+		return true
+	case terminal.KeyHome:
+		v.show(0)
+		return true
+	case terminal.KeyEnd:
+		v.show(len(v.slides.paths) - 1)
+		return true
+	case 's':
+		v.playing = !v.playing
+		return true
+	}
+
+	if v.img == nil {
+		return false
+	}
+	bounds := v.img.Bounds()
+	panX := float64(bounds.Dx()) / v.zoom * panStep
+	panY := float64(bounds.Dy()) / v.zoom * panStep
+
+	switch key {
+	case terminal.KeyLeft, 'h':
+		v.pan(-panX, 0)
+	case terminal.KeyRight, 'l':
+		v.pan(panX, 0)
+	case terminal.KeyUp, 'k':
+		v.pan(0, -panY)
+	case terminal.KeyDown, 'j':
+		v.pan(0, panY)
+	case '+', '=':
+		v.zoom = math.Min(v.zoom*zoomStep, maxZoom)
+		v.pan(0, 0)
+	case '-', '_':
+		v.zoom = math.Max(v.zoom/zoomStep, 1)
+		v.pan(0, 0)
+	case '0':
+		v.resetView()
+	case 't':
+		v.themeIndex = (v.themeIndex + 1) % len(theme.Names())
+	case 'T':
+		v.themeIndex = (v.themeIndex + len(theme.Names()) - 1) % len(theme.Names())
+	case 'c':
+		v.convert.Color = !v.convert.Color
+	case 'e':
+		v.convert.DisableEdges = !v.convert.DisableEdges
+	case '[':
+		v.convert.EdgeCutoff = math.Max(v.convert.EdgeCutoff-cutoffStep, 0)
+	case ']':
+		v.convert.EdgeCutoff += cutoffStep
+	case '<', ',':
+		v.convert.Contrast = math.Max(v.convert.Contrast-contrastStep, contrastStep)
+	case '>', '.':
+		v.convert.Contrast += contrastStep
+	case '?':
+		v.help = !v.help
+	default:
+		return false
+	}
+	return true
+}
+
+func (v *Viewer) resetView() {
+	if v.img == nil {
+		return
+	}
+	bounds := v.img.Bounds()
+	v.zoom = 1
+	v.centerX = float64(bounds.Min.X) + float64(bounds.Dx())/2
+	v.centerY = float64(bounds.Min.Y) + float64(bounds.Dy())/2
+}
+
+// pan moves the visible area, keeping it inside the image.
+func (v *Viewer) pan(dx, dy float64) {
+	bounds := v.img.Bounds()
+	halfW := float64(bounds.Dx()) / v.zoom / 2
+	halfH := float64(bounds.Dy()) / v.zoom / 2
+	v.centerX = clamp(v.centerX+dx, float64(bounds.Min.X)+halfW, float64(bounds.Max.X)-halfW)
+	v.centerY = clamp(v.centerY+dy, float64(bounds.Min.Y)+halfH, float64(bounds.Max.Y)-halfH)
+}
+
+// visible returns the part of the source image currently in view.
+func (v *Viewer) visible() image.Rectangle {
+	bounds := v.img.Bounds()
+	halfW := float64(bounds.Dx()) / v.zoom / 2
+	halfH := float64(bounds.Dy()) / v.zoom / 2
+	r := image.Rect(
+		int(math.Round(v.centerX-halfW)), int(math.Round(v.centerY-halfH)),
+		int(math.Round(v.centerX+halfW)), int(math.Round(v.centerY+halfH)),
+	)
+	if r.Dx() < 1 {
+		r.Max.X = r.Min.X + 1
+	}
+	if r.Dy() < 1 {
+		r.Max.Y = r.Min.Y + 1
+	}
+	return r
+}
+
+// draw converts the visible area at the current settings and redraws the
+// screen, with a status line at the bottom.
+func (v *Viewer) draw() error {
+	size, err := terminal.GetTerminalSize()
+	if err != nil {
+		debug.Log("Viewer: could not detect terminal size: %v", err)
+	}
+	if size.Width < 1 || size.Height < 2 {
+		return nil
+	}
+
+	t, _ := theme.Named(theme.Names()[v.themeIndex])
+	if v.invert {
+		t = theme.NewInvertedTheme(t)
+	}
+	v.convert.Theme = t
+
+	screen := frame.New(size.Width, size.Height)
+	if v.img != nil {
+		f := converter.Convert(imageio.Crop(v.img, v.visible()), size.Width, size.Height-1, v.convert)
+		screen.Blit(f, (size.Width-f.Width)/2, (size.Height-1-f.Height)/2)
+	} else {
+		msg := fmt.Sprintf("cannot display %s: %v", imageio.Name(v.current.path), v.current.err)
+		screen.SetText(max((size.Width-len(msg))/2, 0), (size.Height-1)/2, msg)
+	}
+	v.drawStatus(screen)
+
+	return v.renderer.Update(screen)
+}
+
+// drawStatus draws the caption (file name, position in the slideshow and
+// image dimensions) and the current settings into the last row.
+func (v *Viewer) drawStatus(screen *frame.Frame) {
+	y := screen.Height - 1
+
+	text := " " + imageio.Name(v.current.path)
+	if n := len(v.slides.paths); n > 1 {
+		text = fmt.Sprintf(" [%d/%d]%s", v.index+1, n, text)
+		if v.playing {
+			text += " (playing)"
+		}
+	}
+	if v.img != nil {
+		bounds := v.img.Bounds()
+		view := v.visible()
+		text += fmt.Sprintf("  %dx%d", bounds.Dx(), bounds.Dy())
+		if v.zoom > 1 {
+			text += fmt.Sprintf("  view %dx%d+%d+%d zoom %.1fx", view.Dx(), view.Dy(), view.Min.X-bounds.Min.X, view.Min.Y-bounds.Min.Y, v.zoom)
+		}
+	}
+	text += fmt.Sprintf("  theme %s  cutoff %.0f  contrast %.1f  color %s  edges %s  (? help, q quit)",
+		v.convert.Theme.Name(), v.convert.EdgeCutoff, v.convert.Contrast,
+		onOff(v.convert.Color), onOff(!v.convert.DisableEdges))
+	if v.help {
+		text = helpText
+	}
+
+	screen.EnableColors()
+	screen.EnableBackgrounds()
+	for x := 0; x < screen.Width; x++ {
+		screen.Set(x, y, ' ')
+		screen.SetColor(x, y, statusForeground)
+		screen.SetBackground(x, y, statusBackground)
+	}
+	screen.SetText(0, y, text)
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -1,36 +1,98 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"github.com/kozmaoliver/asciify/internal/converter"
 	"github.com/kozmaoliver/asciify/internal/debug"
-	"github.com/kozmaoliver/asciify/internal/edge"
 	"github.com/kozmaoliver/asciify/internal/frame"
 	"github.com/kozmaoliver/asciify/internal/imageio"
-	"github.com/kozmaoliver/asciify/internal/luminance"
+	"github.com/kozmaoliver/asciify/internal/output"
 	"github.com/kozmaoliver/asciify/internal/terminal"
-	"github.com/kozmaoliver/asciify/internal/theme"
+	"github.com/kozmaoliver/asciify/internal/viewer"
+	"image"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	debugFlag := flag.Bool("debug", false, "Enable debug mode (saves intermediate images and logs)")
-	debugDir := flag.String("debug-dir", "debug_output", "Directory for debug output files")
-	edgeCutoff := flag.Float64("edge-cutoff", 90.0, "Edge detection threshold")
-	bgColorStr := flag.String("bg", "none", "Background color: none, black, or white")
-	colorFlag := flag.Bool("color", false, "Enable colored output using original image colors")
-	flag.Parse()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "view":
+			runView(os.Args[2:])
+			return
+		case "grid":
+			runGrid(os.Args[2:])
+			return
+		}
+	}
 
-	debug.Init(*debugFlag, *debugDir)
+	render := addRenderFlags(flag.CommandLine)
+	var outputPath string
+	flag.StringVar(&outputPath, "o", "", "Write output to a file instead of the terminal (\"-\" for stdout)")
+	flag.StringVar(&outputPath, "output", "", "Same as -o")
+	formatStr := flag.String("format", "auto", "Output file format: auto (by file extension), text, ansi, html, svg, png, gif, cast, json, irc, or bbcode")
+	sauceFlag := flag.Bool("sauce", false, "Append a SAUCE metadata record to ANSI output")
+	sauceTitle := flag.String("sauce-title", "", "SAUCE title (implies -sauce)")
+	sauceAuthor := flag.String("sauce-author", "", "SAUCE author (implies -sauce)")
+	sauceGroup := flag.String("sauce-group", "", "SAUCE group (implies -sauce)")
+	fontFamily := flag.String("font-family", "", "Font family for HTML and SVG output (default: a monospace stack)")
+	fontSize := flag.Int("font-size", 12, "Font size in pixels for HTML and SVG output")
+	htmlFragment := flag.Bool("html-fragment", false, "Write a bare <pre> block instead of a full HTML document")
+	htmlClasses := flag.Bool("html-classes", false, "Use CSS classes for colors instead of inline styles (smaller files)")
+	scale := flag.Int("scale", 1, "Integer magnification for PNG and GIF output")
+	ircExtended := flag.Bool("irc-extended", false, "Use all 99 mIRC colors instead of the standard 16 for IRC output")
+	frameDelay := flag.Duration("frame-delay", frame.DefaultDelay, "Frame duration when several images are combined into an animation")
+	flag.Parse()
 
-	if flag.NArg() < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <image-path>\n", os.Args[0])
+	args, err := imageio.ExpandPaths(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if flag.NArg() > 0 && len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no images found\n")
+		os.Exit(1)
+	}
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <image-path|directory> [...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s view [flags] <image-path|directory> [...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s grid [flags] <image-path|directory> [...]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nFlags:\n")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	imagePath := flag.Arg(0)
+	var format output.Format
+	if outputPath != "" {
+		format = output.FormatFromPath(outputPath)
+		if *formatStr != "auto" {
+			format, err = output.ParseFormat(*formatStr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	convertOpts, bgColor, colorDepth := render.setup(outputPath, format)
+	useColor := convertOpts.Color
+
+	if outputPath == "" && terminal.IsTerminal(os.Stdout) && hasDirectory(flag.Args()) {
+		// A directory can hold any number of images; browse them in the
+		// viewer, which decodes them as they come up, rather than
+		// converting them all into one animation first.
+		viewImages(args, viewer.Options{
+			Decode:     render.decodeOptions(),
+			Convert:    convertOpts,
+			Background: bgColor,
+			Depth:      colorDepth,
+		})
+		return
+	}
 
 	// Get terminal size
 	size, err := terminal.GetTerminalSize()
@@ -39,128 +101,206 @@ func main() {
 	}
 	debug.Log("Terminal size: %dx%d", size.Width, size.Height)
 
-	// Load image
-	img, err := imageio.LoadImage(imagePath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading image: %v\n", err)
-		os.Exit(1)
+	var frames []*frame.Frame
+	var images []image.Image
+	var delays []time.Duration
+	var metadata *output.Metadata
+
+	if len(args) == 1 && output.CanRead(args[0]) {
+		// Re-render a previously exported frame.
+		f, err := output.ReadFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading frame: %v\n", err)
+			os.Exit(1)
+		}
+		debug.Log("Loaded frame: %dx%d", f.Width, f.Height)
+		useColor = f.Colors != nil && colorAllowed(colorDepth, outputPath, format)
+		frames = []*frame.Frame{f}
+	} else {
+		// Load image, animation or image sequence
+		start := time.Now()
+		var anim *imageio.Animation
+		if len(args) > 1 {
+			anim, err = imageio.LoadSequence(args, *frameDelay, render.decodeOptions())
+		} else {
+			anim, err = imageio.LoadAnimation(args[0], render.decodeOptions())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading image: %v\n", err)
+			os.Exit(1)
+		}
+		loadTime := time.Since(start)
+		debug.Log("Loaded %d frame(s) in %v", len(anim.Frames), loadTime)
+
+		// Only animated formats and terminal playback need every frame
+		// converted.
+		images = anim.Frames
+		if !format.Animated() && !(outputPath == "" && terminal.IsTerminal(os.Stdout)) {
+			images = images[:1]
+		}
+		start = time.Now()
+		prepared, frameOpts := converter.Prepare(images, size.Width, size.Height, convertOpts)
+		frames = make([]*frame.Frame, len(prepared))
+		for i, img := range prepared {
+			frames[i] = converter.Convert(img, size.Width, size.Height, frameOpts)
+		}
+		delays = anim.Delays
+		convertTime := time.Since(start)
+
+		bounds := anim.Frames[0].Bounds()
+		metadata = &output.Metadata{
+			Theme:      convertOpts.Theme.Name(),
+			EdgeCutoff: convertOpts.EdgeCutoff,
+			Source:     &output.Dimensions{Width: bounds.Dx(), Height: bounds.Dy()},
+			Timings: map[string]float64{
+				"load":    milliseconds(loadTime),
+				"convert": milliseconds(convertTime),
+			},
+		}
 	}
-	bounds := img.Bounds()
-	debug.Log("Loaded image: %dx%d", bounds.Dx(), bounds.Dy())
-	debug.SaveImage(img, "01_original")
-
-	// Resize for terminal
-	resized := imageio.ResizeForTerminal(img, size.Width, size.Height)
-	resizedBounds := resized.Bounds()
-	debug.Log("Resized image: %dx%d", resizedBounds.Dx(), resizedBounds.Dy())
-	debug.SaveImage(resized, "02_resized")
-
-	// Create frame
-	resizedBounds = resized.Bounds()
-	frameWidth := resizedBounds.Dx()
-	frameHeight := resizedBounds.Dy()
-	f := frame.New(frameWidth, frameHeight)
-
-	// Enable color storage if color output is requested
-	if *colorFlag {
-		f.EnableColors()
-	}
-
-	// Step 1: Generate ASCII image based on luminance
-	debug.Log("Step 1: Generating ASCII from luminance")
-	defaultTheme := theme.NewDefaultTheme()
-	chars := defaultTheme.Characters()
-	brightestChar := defaultTheme.BrightestChar()
-	debug.Log("Theme characters: %d levels", len(chars))
-	
-	for y := 0; y < frameHeight; y++ {
-		for x := 0; x < frameWidth; x++ {
-			c := resized.At(resizedBounds.Min.X+x, resizedBounds.Min.Y+y)
-			
-			if *colorFlag {
-				f.SetColor(x, y, c)
-				f.Set(x, y, brightestChar) 
-				continue
-			}
-			
-			lum := luminance.Luminance(c)
-			
-			index := int(lum * float64(len(chars)))
-			if index >= len(chars) {
-				index = len(chars) - 1
-			}
-			if index < 0 {
-				index = 0
+
+	if outputPath != "" {
+		opts := output.Options{
+			Background:  bgColor,
+			Color:       useColor,
+			Depth:       colorDepth,
+			FontFamily:  *fontFamily,
+			FontSize:    *fontSize,
+			Fragment:    *htmlFragment,
+			CSSClasses:  *htmlClasses,
+			Scale:       *scale,
+			Metadata:    metadata,
+			IRCExtended: *ircExtended,
+		}
+		if *sauceFlag || *sauceTitle != "" || *sauceAuthor != "" || *sauceGroup != "" {
+			opts.Sauce = &output.Sauce{
+				Title:  *sauceTitle,
+				Author: *sauceAuthor,
+				Group:  *sauceGroup,
 			}
-			
-			f.Set(x, y, chars[index])
 		}
-	}
-	debug.Log("Generated luminance-based ASCII frame: %dx%d", frameWidth, frameHeight)
 
-	frameCells := make([][]rune, frameHeight)
-	for y := 0; y < frameHeight; y++ {
-		frameCells[y] = make([]rune, frameWidth)
-		for x := 0; x < frameWidth; x++ {
-			frameCells[y][x] = f.Get(x, y)
+		debug.Log("Writing %s output to %s", format, outputPath)
+		if err := output.WriteAnimationFile(outputPath, frames, delays, format, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	} else if len(frames) > 1 {
+		debug.Log("Playing %d frames (bg: %s, color: %v)", len(frames), bgColor, useColor)
+		renderer := terminal.NewRenderer(os.Stdout, bgColor, useColor, colorDepth)
+		if err := play(renderer, images, delays, frames, convertOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		// Render to terminal
+		debug.Log("Rendering to terminal (bg: %s, color: %v)", bgColor, useColor)
+		if err := terminal.RenderFrame(frames[0], bgColor, useColor, colorDepth); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering: %v\n", err)
+			os.Exit(1)
 		}
 	}
-	debug.SaveFrameAsImage(frameCells, "03_luminance_ascii")
 
-	// Step 2: Apply Difference of Gaussians to enhance edges for detection
-	debug.Log("Step 2: Applying Difference of Gaussians (sigma1=0.5, sigma2=1.5)")
-	dogImage := imageio.DifferenceOfGaussians(resized, 0.5, 1.5)
-	debug.SaveImage(dogImage, "04_dog_filtered")
+	// Save debug logs if debug mode is enabled
+	if debug.IsEnabled() {
+		debug.WriteLogsToFile("debug.log")
+		debug.Log("Debug session complete. Check %s for output files", *render.debugDir)
+	}
+}
 
-	// Step 3: Detect edges on DoG-filtered image
-	debug.Log("Step 3: Detecting edges with Sobel filter")
-	edges := edge.Sobel(dogImage)
-	
-	edgeCount := 0
+// lightBackground reports whether the output background is light, in which
+// case the luminance ramp has to be inverted. An explicit light or dark
+// mode wins over the -bg colour, which wins over asking the terminal.
+func lightBackground(mode string, bgColor terminal.BackgroundColor) bool {
+	switch mode {
+	case "light":
+		return true
+	case "dark":
+		return false
+	}
 
-	// Step 4: Replace edge positions with edge characters
-	debug.Log("Step 4: Applying edges with cutoff threshold: %.2f", *edgeCutoff)
-	for y := 0; y < frameHeight; y++ {
-		for x := 0; x < frameWidth; x++ {
-			edgeInfo := edges[y][x]
-			if edgeInfo.Strength > *edgeCutoff {
-				edgeChar := edge.EdgeChar(edgeInfo.Direction)
-				f.Set(x, y, edgeChar)
-				edgeCount++
-			}
-		}
+	switch bgColor {
+	case terminal.BgWhite:
+		return true
+	case terminal.BgBlack:
+		return false
 	}
-	debug.Log("Applied %d edge characters (%.2f%% of pixels)", edgeCount, float64(edgeCount)*100.0/float64(frameWidth*frameHeight))
 
-	frameCells = make([][]rune, frameHeight)
-	for y := 0; y < frameHeight; y++ {
-		frameCells[y] = make([]rune, frameWidth)
-		for x := 0; x < frameWidth; x++ {
-			frameCells[y][x] = f.Get(x, y)
-		}
+	if mode == "off" {
+		return false
 	}
-	debug.SaveFrameAsImage(frameCells, "05_final_with_edges")
 
-	// Parse background color
-	var bgColor terminal.BackgroundColor
-	switch *bgColorStr {
-	case "black":
-		bgColor = terminal.BgBlack
-	case "white":
-		bgColor = terminal.BgWhite
-	case "none":
-		fallthrough
-	default:
-		bgColor = terminal.BgNone
+	if !terminal.IsTerminal(os.Stdout) {
+		return false
 	}
+	bg, err := terminal.QueryBackground(terminal.DefaultQueryTimeout)
+	if err != nil {
+		debug.Log("Background detection failed: %v", err)
+		return false
+	}
+	debug.Log("Terminal background: #%02x%02x%02x", bg.R, bg.G, bg.B)
+	return terminal.IsLight(bg)
+}
 
-	// Render to terminal
-	debug.Log("Rendering to terminal (bg: %s, color: %v)", *bgColorStr, *colorFlag)
-	terminal.RenderFrame(f, bgColor, *colorFlag)
-	
-	// Save debug logs if debug mode is enabled
-	if debug.IsEnabled() {
-		debug.WriteLogsToFile("debug.log")
-		debug.Log("Debug session complete. Check %s for output files", *debugDir)
+// play loops through the frames until interrupted, redrawing only the
+// cells that change between frames. When the terminal is resized the
+// decoded images are prepared again for the new size and converted one by
+// one as they come up.
+func play(renderer *terminal.Renderer, images []image.Image, delays []time.Duration, frames []*frame.Frame, opts converter.Options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resizes, stopResize := terminal.WatchResize(terminal.DefaultResizeDebounce)
+	defer stopResize()
+	var size terminal.Size
+
+	// Hide the cursor while playing; afterwards show it below the frame.
+	height := 0
+	fmt.Print("\x1b[?25l")
+	defer func() {
+		fmt.Printf("\x1b[0m\x1b[%d;1H\x1b[?25h", height+1)
+	}()
+
+	var prepared []image.Image
+	var frameOpts converter.Options
+	next := time.Now()
+	for i := 0; ; i = (i + 1) % len(frames) {
+		if frames[i] == nil {
+			if prepared == nil {
+				prepared, frameOpts = converter.Prepare(images, size.Width, size.Height, opts)
+			}
+			frames[i] = converter.Convert(prepared[i], size.Width, size.Height, frameOpts)
+		}
+		if err := renderer.Update(frames[i]); err != nil {
+			return err
+		}
+		height = frames[i].Height
+
+		delay := frame.DefaultDelay
+		if i < len(delays) && delays[i] > 0 {
+			delay = delays[i]
+		}
+		// Schedule against the previous deadline so slow frames do not
+		// make the animation drift.
+		next = next.Add(delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case size = <-resizes:
+			debug.Log("Terminal resized to %dx%d", size.Width, size.Height)
+			// Font size changes show up as a new cell size.
+			if aspect := size.CellAspect(); aspect > 0 {
+				opts.CellAspect = aspect
+			}
+			clear(frames)
+			prepared = nil
+			renderer.Invalidate()
+			next = time.Now()
+		case <-time.After(time.Until(next)):
+		}
 	}
 }
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kozmaoliver/asciify/internal/debug"
+	"github.com/kozmaoliver/asciify/internal/imageio"
+	"github.com/kozmaoliver/asciify/internal/terminal"
+	"github.com/kozmaoliver/asciify/internal/viewer"
+	"os"
+)
+
+// runView implements "asciify view": an interactive full-screen viewer.
+func runView(args []string) {
+	flags := flag.NewFlagSet("view", flag.ExitOnError)
+	render := addRenderFlags(flags)
+	interval := flags.Duration("interval", 0, "Advance the slideshow automatically at this interval (s toggles playback)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s view [flags] <image-path|directory> [...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nKeys: arrows/hjkl pan, +/- zoom, 0 reset, n/p next/previous image,\n")
+		fmt.Fprintf(os.Stderr, "s slideshow, t theme, c color, e edges, [ ] edge cutoff, < > contrast,\n")
+		fmt.Fprintf(os.Stderr, "? help, q quit\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if !terminal.IsTerminal(os.Stdout) {
+		fmt.Fprintf(os.Stderr, "Error: view needs a terminal\n")
+		os.Exit(1)
+	}
+
+	convertOpts, bgColor, colorDepth := render.setup("", "")
+
+	paths, err := imageio.ExpandPaths(flags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no images found\n")
+		os.Exit(1)
+	}
+
+	viewImages(paths, viewer.Options{
+		Decode:     render.decodeOptions(),
+		Convert:    convertOpts,
+		Background: bgColor,
+		Depth:      colorDepth,
+		Interval:   *interval,
+	})
+}
+
+// viewImages shows the images in the interactive viewer, exiting on error.
+func viewImages(paths []string, opts viewer.Options) {
+	debug.Log("Viewing %d image(s)", len(paths))
+	if err := viewer.New(paths, opts).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if debug.IsEnabled() {
+		debug.WriteLogsToFile("debug.log")
+	}
+}
+
+// hasDirectory reports whether any of the paths is a directory.
+func hasDirectory(paths []string) bool {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}